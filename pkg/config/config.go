@@ -35,6 +35,8 @@ import (
 
 type CongestionControlProbeMode string
 type StreamTrackerType string
+type SIPLogNumbers string
+type SIPTrunkDeletePolicy string
 
 const (
 	generatedCLIFlagUsage = "generated"
@@ -45,6 +47,15 @@ const (
 	StreamTrackerTypePacket StreamTrackerType = "packet"
 	StreamTrackerTypeFrame  StreamTrackerType = "frame"
 
+	SIPLogNumbersFull   SIPLogNumbers = "full"
+	SIPLogNumbersLast4  SIPLogNumbers = "last4"
+	SIPLogNumbersHashed SIPLogNumbers = "hashed"
+	SIPLogNumbersNone   SIPLogNumbers = "none"
+
+	SIPTrunkDeleteKeepRules SIPTrunkDeletePolicy = "keep_rules"
+	SIPTrunkDeleteBlock     SIPTrunkDeletePolicy = "block"
+	SIPTrunkDeleteCascade   SIPTrunkDeletePolicy = "cascade"
+
 	StatsUpdateInterval          = time.Second * 10
 	TelemetryStatsUpdateInterval = time.Second * 30
 )
@@ -296,6 +307,66 @@ type IngressConfig struct {
 }
 
 type SIPConfig struct {
+	// how phone numbers are written to logs, error messages and the dispatch failure history: full, last4, hashed or none.
+	// hashed numbers are pseudonymous, not anonymous: they are an HMAC keyed by log_numbers_key, which is required
+	// for that mode and must be kept secret, since phone numbers are few enough to enumerate
+	LogNumbers    SIPLogNumbers `yaml:"log_numbers,omitempty"`
+	LogNumbersKey string        `yaml:"log_numbers_key,omitempty"`
+
+	// how long a room found missing for an inbound call is remembered before it's checked again, 0 to disable
+	// caching. rooms that exist are checked for every call
+	RoomCacheTTL time.Duration `yaml:"room_cache_ttl,omitempty"`
+
+	// outbound dial pacing, applied to each trunk separately. 0 means unlimited. a dial counts against
+	// max_parallel_dials until CreateSIPParticipant returns, the call ringing on the SIP node is not counted
+	MaxDialsPerSecond float64 `yaml:"max_dials_per_second,omitempty"`
+	MaxParallelDials  int     `yaml:"max_parallel_dials,omitempty"`
+	// number of dials allowed to wait for a slot before new ones are rejected, 0 means unbounded
+	MaxDialQueueLength int `yaml:"max_dial_queue_length,omitempty"`
+	// how long CreateSIPParticipant may take before the dial is abandoned, 0 means no limit beyond the request context
+	DialTimeout time.Duration `yaml:"dial_timeout,omitempty"`
+
+	// what happens to dispatch rules referencing a deleted trunk: keep_rules (default) leaves them as they are,
+	// block fails the delete, cascade removes the trunk from the rules and deletes rules left without trunks
+	TrunkDeletePolicy SIPTrunkDeletePolicy `yaml:"trunk_delete_policy,omitempty"`
+
+	// limits on the number of trunks, dispatch rules and participants that can exist at once, 0 means unlimited
+	MaxTrunks        int `yaml:"max_trunks,omitempty"`
+	MaxDispatchRules int `yaml:"max_dispatch_rules,omitempty"`
+	MaxParticipants  int `yaml:"max_participants,omitempty"`
+
+	// concurrent store reads for inbound call setup, which wait for a slot, and for list requests, which are
+	// rejected when over the limit. 0 means unlimited
+	MaxStoreCallSetupOps int `yaml:"max_store_call_setup_ops,omitempty"`
+	MaxStoreListOps      int `yaml:"max_store_list_ops,omitempty"`
+
+	// DTMF requests allowed per second for a single participant, 0 means unlimited
+	DTMFRateLimit float64 `yaml:"dtmf_rate_limit,omitempty"`
+	DTMFBurst     int     `yaml:"dtmf_burst,omitempty"`
+
+	// number of recent inbound calls that matched no dispatch rule kept in the store for diagnosis, 0 to disable
+	DispatchFailureHistory int `yaml:"dispatch_failure_history,omitempty"`
+
+	// events kept for each participant and how long they are kept after the last one, 0 events disables recording
+	ParticipantEventLimit int           `yaml:"participant_event_limit,omitempty"`
+	ParticipantEventTTL   time.Duration `yaml:"participant_event_ttl,omitempty"`
+}
+
+func (c *SIPConfig) Validate() error {
+	switch c.LogNumbers {
+	case "", SIPLogNumbersFull, SIPLogNumbersLast4, SIPLogNumbersHashed, SIPLogNumbersNone:
+	default:
+		return fmt.Errorf("invalid log_numbers value %q", c.LogNumbers)
+	}
+	if c.LogNumbers == SIPLogNumbersHashed && c.LogNumbersKey == "" {
+		return errors.New("log_numbers_key is required when log_numbers is hashed")
+	}
+	switch c.TrunkDeletePolicy {
+	case "", SIPTrunkDeleteKeepRules, SIPTrunkDeleteBlock, SIPTrunkDeleteCascade:
+	default:
+		return fmt.Errorf("invalid trunk_delete_policy value %q", c.TrunkDeletePolicy)
+	}
+	return nil
 }
 
 // not exposed to YAML
@@ -477,6 +548,15 @@ var DefaultConfig = Config{
 	Logging: LoggingConfig{
 		PionLevel: "error",
 	},
+	SIP: SIPConfig{
+		RoomCacheTTL:  5 * time.Second,
+		DTMFRateLimit: 5,
+		DTMFBurst:     10,
+
+		DispatchFailureHistory: 100,
+		ParticipantEventLimit:  100,
+		ParticipantEventTTL:    24 * time.Hour,
+	},
 	TURN: TURNConfig{
 		Enabled: false,
 	},
@@ -522,6 +602,10 @@ func NewConfig(confString string, strictMode bool, c *cli.Context, baseFlags []c
 		return nil, fmt.Errorf("could not validate RTC config: %v", err)
 	}
 
+	if err := conf.SIP.Validate(); err != nil {
+		return nil, fmt.Errorf("could not validate SIP config: %v", err)
+	}
+
 	if c != nil {
 		if err := conf.updateFromCLI(c, baseFlags); err != nil {
 			return nil, err
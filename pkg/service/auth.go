@@ -176,6 +176,12 @@ func EnsureIngressAdminPermission(ctx context.Context) error {
 	return nil
 }
 
+// EnsureSIPAdminPermission checks for permission to manage SIP trunks, dispatch rules and participants.
+// Until tokens carry a dedicated SIP grant, this requires the same roomCreate grant as server-side room management.
+func EnsureSIPAdminPermission(ctx context.Context) error {
+	return EnsureCreatePermission(ctx)
+}
+
 // wraps authentication errors around Twirp
 func twirpAuthError(err error) error {
 	return twirp.NewError(twirp.Unauthenticated, err.Error())
@@ -38,4 +38,17 @@ var (
 	ErrSIPTrunkNotFound        = psrpc.NewErrorf(psrpc.NotFound, "requested sip trunk does not exist")
 	ErrSIPDispatchRuleNotFound = psrpc.NewErrorf(psrpc.NotFound, "requested sip dispatch rule does not exist")
 	ErrSIPParticipantNotFound  = psrpc.NewErrorf(psrpc.NotFound, "requested sip participant does not exist")
+	ErrSIPScheduledCallMissing = psrpc.NewErrorf(psrpc.NotFound, "requested sip scheduled call does not exist")
+	ErrSIPInvalidRoomName      = psrpc.NewErrorf(psrpc.InvalidArgument, "invalid sip dispatch rule room name")
+	ErrSIPRoomNotAllowed       = psrpc.NewErrorf(psrpc.FailedPrecondition, "sip dispatch rule room does not exist and cannot be created")
+	ErrSIPRoomLimitReached     = psrpc.NewErrorf(psrpc.ResourceExhausted, "sip dispatch rule room has reached its capacity")
+	ErrSIPSourceNotAllowed     = psrpc.NewErrorf(psrpc.PermissionDenied, "sip call source address is not allowed for this number")
+	ErrSIPTrunkInUse           = psrpc.NewErrorf(psrpc.FailedPrecondition, "sip trunk is used by dispatch rules")
+	ErrSIPTrunkQuota           = psrpc.NewErrorf(psrpc.ResourceExhausted, "sip trunk quota exceeded")
+	ErrSIPDispatchRuleQuota    = psrpc.NewErrorf(psrpc.ResourceExhausted, "sip dispatch rule quota exceeded")
+	ErrSIPParticipantQuota     = psrpc.NewErrorf(psrpc.ResourceExhausted, "sip participant quota exceeded")
+	ErrSIPDialQueueFull        = psrpc.NewErrorf(psrpc.ResourceExhausted, "too many sip calls waiting to be dialed on this trunk")
+	ErrSIPDTMFRateLimited      = psrpc.NewErrorf(psrpc.ResourceExhausted, "too many dtmf requests for sip participant")
+	ErrSIPStoreConflict        = psrpc.NewErrorf(psrpc.Aborted, "sip config was changed concurrently, try again")
+	ErrSIPStoreBusy            = psrpc.NewErrorf(psrpc.ResourceExhausted, "too many concurrent sip list requests, try again later")
 )
@@ -91,6 +91,55 @@ type SIPStore interface {
 
 	StoreSIPParticipant(ctx context.Context, info *livekit.SIPParticipantInfo) error
 	LoadSIPParticipant(ctx context.Context, sipParticipantID string) (*livekit.SIPParticipantInfo, error)
+	// LoadSIPParticipants returns the participants that exist among the given IDs
+	LoadSIPParticipants(ctx context.Context, sipParticipantIDs []string) ([]*livekit.SIPParticipantInfo, error)
 	ListSIPParticipant(ctx context.Context) ([]*livekit.SIPParticipantInfo, error)
 	DeleteSIPParticipant(ctx context.Context, info *livekit.SIPParticipantInfo) error
+
+	AppendSIPParticipantEvent(ctx context.Context, sipParticipantID string, event *SIPParticipantEvent, maxEvents int, ttl time.Duration) error
+	ListSIPParticipantEvents(ctx context.Context, sipParticipantID string) ([]*SIPParticipantEvent, error)
+
+	// AppendSIPDispatchFailure adds a failure to the history shared by all nodes, keeping the newest maxFailures
+	AppendSIPDispatchFailure(ctx context.Context, failure *SIPDispatchFailure, maxFailures int) error
+	// ListSIPDispatchFailures returns the failure history, newest first
+	ListSIPDispatchFailures(ctx context.Context) ([]*SIPDispatchFailure, error)
+
+	RecordSIPDispatchRuleMatch(ctx context.Context, sipDispatchRuleID string, at time.Time) error
+	// SetSIPDispatchRuleRoomMissing records when the rule's room was first found missing, a zero time clears it
+	SetSIPDispatchRuleRoomMissing(ctx context.Context, sipDispatchRuleID string, since time.Time) error
+	// MarkSIPDispatchRulesSeen records at as the time the rules were first seen, keeping earlier times
+	MarkSIPDispatchRulesSeen(ctx context.Context, sipDispatchRuleIDs []string, at time.Time) error
+	LoadSIPDispatchRuleActivity(ctx context.Context) (map[string]*SIPDispatchRuleActivity, error)
+
+	StoreSIPScheduledCall(ctx context.Context, call *SIPScheduledCall) error
+	// ListSIPScheduledCalls returns all calls that have not completed, including the ones being dialed
+	ListSIPScheduledCalls(ctx context.Context) ([]*SIPScheduledCall, error)
+	// ClaimSIPScheduledCalls leases up to limit calls that are due, or whose lease expired before now.
+	// A claimed call is only removed by CompleteSIPScheduledCall, so a call whose server stopped is claimed again.
+	ClaimSIPScheduledCalls(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*SIPScheduledCall, error)
+	CompleteSIPScheduledCall(ctx context.Context, id string) error
+	// CancelSIPScheduledCall removes a call that has not been claimed yet, or returns ErrSIPScheduledCallMissing
+	CancelSIPScheduledCall(ctx context.Context, id string) error
+
+	// RunSIPTxn applies the changes made by fn atomically. fn is called again if another writer changes trunks,
+	// rules or participants it read before the transaction completes, so the reads of the last call are consistent.
+	RunSIPTxn(ctx context.Context, fn func(tx SIPTxn) error) error
+}
+
+// SIPTxn reads and changes trunks, dispatch rules and participants as a single unit. Changes only become visible
+// once the transaction completes.
+type SIPTxn interface {
+	StoreSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error
+	LoadSIPTrunk(ctx context.Context, sipTrunkID string) (*livekit.SIPTrunkInfo, error)
+	ListSIPTrunk(ctx context.Context) ([]*livekit.SIPTrunkInfo, error)
+	DeleteSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error
+
+	StoreSIPDispatchRule(ctx context.Context, info *livekit.SIPDispatchRuleInfo) error
+	LoadSIPDispatchRule(ctx context.Context, sipDispatchRuleID string) (*livekit.SIPDispatchRuleInfo, error)
+	ListSIPDispatchRule(ctx context.Context) ([]*livekit.SIPDispatchRuleInfo, error)
+	DeleteSIPDispatchRule(ctx context.Context, info *livekit.SIPDispatchRuleInfo) error
+
+	StoreSIPParticipant(ctx context.Context, info *livekit.SIPParticipantInfo) error
+	LoadSIPParticipant(ctx context.Context, sipParticipantID string) (*livekit.SIPParticipantInfo, error)
+	ListSIPParticipant(ctx context.Context) ([]*livekit.SIPParticipantInfo, error)
 }
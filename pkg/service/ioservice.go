@@ -25,6 +25,9 @@ import (
 	"github.com/livekit/protocol/rpc"
 	"github.com/livekit/psrpc"
 
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/routing/selector"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 )
 
@@ -34,8 +37,16 @@ type IOInfoService struct {
 	es        EgressStore
 	is        IngressStore
 	ss        SIPStore
+	ra        RoomAllocator
+	router    routing.Router
+	limits    config.LimitConfig
+	sipConf   *config.SIPConfig
+	sipRooms  *sipRoomCache
 	telemetry telemetry.TelemetryService
 
+	sipBudget  *sipStoreBudget
+	sipMatches *sipRuleMatches
+
 	shutdown chan struct{}
 }
 
@@ -44,14 +55,27 @@ func NewIOInfoService(
 	es EgressStore,
 	is IngressStore,
 	ss SIPStore,
+	ra RoomAllocator,
+	router routing.Router,
+	conf *config.Config,
 	ts telemetry.TelemetryService,
 ) (*IOInfoService, error) {
 	s := &IOInfoService{
 		es:        es,
 		is:        is,
 		ss:        ss,
+		ra:        ra,
+		router:    router,
+		limits:    conf.Limit,
+		sipConf:   &conf.SIP,
 		telemetry: ts,
 		shutdown:  make(chan struct{}),
+
+		sipBudget:  newSIPStoreBudget(sipStorePathCallSetup, conf.SIP.MaxStoreCallSetupOps, true),
+		sipMatches: newSIPRuleMatches(),
+	}
+	if ra != nil {
+		s.sipRooms = newSIPRoomCache(conf.SIP.RoomCacheTTL, s.validateSIPRoom)
 	}
 
 	if bus != nil {
@@ -65,6 +89,19 @@ func NewIOInfoService(
 	return s, nil
 }
 
+// validateSIPRoom checks that an inbound call is allowed to join the room, and that the node hosting it has capacity.
+func (s *IOInfoService) validateSIPRoom(ctx context.Context, roomName livekit.RoomName) error {
+	if err := s.ra.ValidateCreateRoom(ctx, roomName); err != nil {
+		return err
+	}
+	if s.router != nil {
+		if node, err := s.router.GetNodeForRoom(ctx, roomName); err == nil && selector.LimitsReached(s.limits, node.Stats) {
+			return routing.ErrNodeLimitReached
+		}
+	}
+	return nil
+}
+
 func (s *IOInfoService) Start() error {
 	if s.es != nil {
 		rs := s.es.(*RedisStore)
@@ -74,6 +111,9 @@ func (s *IOInfoService) Start() error {
 			return err
 		}
 	}
+	if s.ss != nil {
+		go s.sipRuleMatchWorker()
+	}
 
 	return nil
 }
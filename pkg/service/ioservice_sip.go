@@ -2,16 +2,57 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"math"
+	"net"
 	"regexp"
 	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	sutils "github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/rpc"
 )
 
+// redactSIPNumber formats a phone number for logs and error messages according to the privacy setting.
+// Hashed numbers use an HMAC with the deployment's key, a plain hash of a phone number is easily reversed.
+func redactSIPNumber(conf *config.SIPConfig, number string) string {
+	if conf == nil {
+		return number
+	}
+	switch conf.LogNumbers {
+	case "", config.SIPLogNumbersFull:
+		return number
+	case config.SIPLogNumbersLast4:
+		if len(number) <= 4 {
+			return "****"
+		}
+		return "***" + number[len(number)-4:]
+	case config.SIPLogNumbersHashed:
+		if conf.LogNumbersKey == "" {
+			return "<redacted>"
+		}
+		mac := hmac.New(sha256.New, []byte(conf.LogNumbersKey))
+		mac.Write([]byte(number))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:8])
+	default:
+		return "<redacted>"
+	}
+}
+
 // sipRulePriority returns sorting priority for dispatch rules. Lower value means higher priority.
 func sipRulePriority(info *livekit.SIPDispatchRuleInfo) int32 {
 	// In all these cases, prefer pin-protected rules.
@@ -101,7 +142,7 @@ func sipSelectDispatch(rules []*livekit.SIPDispatchRuleInfo, req *rpc.EvaluateSI
 		return pinRule, nil
 	}
 	if openCnt > 1 {
-		return nil, fmt.Errorf("Conflicting SIP Dispatch Rules: Matched %d open rules for %q", openCnt, req.CallingNumber)
+		return nil, fmt.Errorf("Conflicting SIP Dispatch Rules: Matched %d open rules", openCnt)
 	}
 	return openRule, nil
 }
@@ -125,20 +166,127 @@ func sipGetPinAndRoom(info *livekit.SIPDispatchRuleInfo) (room, pin string, err
 	return room, pin, nil
 }
 
+// sipValidateRoomName checks that a room name (or prefix) taken from a dispatch rule can be used for a room.
+// The name is left out of the error, since dispatched room names may contain the caller's number.
+func sipValidateRoomName(name string) error {
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("%w: not valid UTF-8", ErrSIPInvalidRoomName)
+	}
+	if strings.TrimSpace(name) != name {
+		return fmt.Errorf("%w: leading or trailing whitespace", ErrSIPInvalidRoomName)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%w: control characters", ErrSIPInvalidRoomName)
+		}
+	}
+	return nil
+}
+
+// sipNormalizeDispatchRule trims room names and prefixes in the rule and validates them.
+func sipNormalizeDispatchRule(rule *livekit.SIPDispatchRule) error {
+	switch r := rule.GetRule().(type) {
+	default:
+		return fmt.Errorf("Unsupported SIP Dispatch Rule: %T", r)
+	case *livekit.SIPDispatchRule_DispatchRuleDirect:
+		r.DispatchRuleDirect.RoomName = strings.TrimSpace(r.DispatchRuleDirect.RoomName)
+		if r.DispatchRuleDirect.RoomName == "" {
+			return fmt.Errorf("%w: room name is required", ErrSIPInvalidRoomName)
+		}
+		return sipValidateRoomName(r.DispatchRuleDirect.RoomName)
+	case *livekit.SIPDispatchRule_DispatchRulePin:
+		r.DispatchRulePin.RoomName = strings.TrimSpace(r.DispatchRulePin.RoomName)
+		if r.DispatchRulePin.RoomName == "" {
+			return fmt.Errorf("%w: room name is required", ErrSIPInvalidRoomName)
+		}
+		return sipValidateRoomName(r.DispatchRulePin.RoomName)
+	case *livekit.SIPDispatchRule_DispatchRuleIndividual:
+		r.DispatchRuleIndividual.RoomPrefix = strings.TrimSpace(r.DispatchRuleIndividual.RoomPrefix)
+		return sipValidateRoomName(r.DispatchRuleIndividual.RoomPrefix)
+	}
+}
+
+// sipRoomError converts a room validation error into one that tells the SIP node why the call cannot join.
+func sipRoomError(roomName string, err error) error {
+	switch {
+	case errors.Is(err, ErrRoomNotFound):
+		return fmt.Errorf("%w: %q", ErrSIPRoomNotAllowed, roomName)
+	case errors.Is(err, routing.ErrNodeLimitReached):
+		return fmt.Errorf("%w: %q", ErrSIPRoomLimitReached, roomName)
+	}
+	return err
+}
+
+// sipParseAddress extracts the IP from an address reported by the SIP node, which may include a port.
+func sipParseAddress(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+// sipMatchAddress checks if the source IP is one of the trunk's inbound addresses. Trunks without addresses match any source.
+func sipMatchAddress(tr *livekit.SIPTrunkInfo, ip net.IP) bool {
+	if len(tr.InboundAddresses) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, addr := range tr.InboundAddresses {
+		if strings.Contains(addr, "/") {
+			_, ipNet, err := net.ParseCIDR(addr)
+			if err != nil {
+				logger.Errorw("cannot parse SIP trunk inbound address", err, "trunkID", tr.SipTrunkId)
+				continue
+			}
+			if ipNet.Contains(ip) {
+				return true
+			}
+		} else if ip.Equal(net.ParseIP(addr)) {
+			return true
+		}
+	}
+	return false
+}
+
+// compiled trunk number patterns. Entries are keyed by the pattern itself,
+// so a trunk whose patterns are updated never matches with the old ones.
+var sipRegexCache, _ = lru.New[string, *regexp.Regexp](1024)
+
+func sipCompileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := sipRegexCache.Get(pattern); ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sipRegexCache.Add(pattern, re)
+	return re, nil
+}
+
 // sipMatchTrunk finds a SIP Trunk definition matching the request.
-// Returns nil if no rules matched or an error if there are conflicting definitions.
-func sipMatchTrunk(trunks []*livekit.SIPTrunkInfo, calling, called string) (*livekit.SIPTrunkInfo, error) {
+// When the source address of the call is known, trunks restricted to other inbound addresses are not considered.
+// Returns nil if no rules matched or an error if there are conflicting definitions, or if the only trunks for the
+// called number are restricted to other addresses.
+func sipMatchTrunk(trunks []*livekit.SIPTrunkInfo, calling, called, srcAddress string) (*livekit.SIPTrunkInfo, error) {
 	var (
 		selectedTrunk   *livekit.SIPTrunkInfo
 		defaultTrunk    *livekit.SIPTrunkInfo
-		defaultTrunkCnt int // to error in case there are multiple ones
+		defaultTrunkCnt int  // to error in case there are multiple ones
+		wrongSource     bool // a trunk for the call exists, but not for this source address
 	)
+	// An unknown source address is not allowed by trunks that restrict inbound addresses.
+	var srcIP net.IP
+	if srcAddress != "" {
+		srcIP = sipParseAddress(srcAddress)
+	}
 	for _, tr := range trunks {
 		// Do not consider it if regexp doesn't match.
 		matches := len(tr.InboundNumbersRegex) == 0
 		for _, reStr := range tr.InboundNumbersRegex {
-			// TODO: we should cache it
-			re, err := regexp.Compile(reStr)
+			re, err := sipCompileRegex(reStr)
 			if err != nil {
 				logger.Errorw("cannot parse SIP trunk regexp", err, "trunkID", tr.SipTrunkId)
 				continue
@@ -151,14 +299,22 @@ func sipMatchTrunk(trunks []*livekit.SIPTrunkInfo, calling, called string) (*liv
 		if !matches {
 			continue
 		}
+		if tr.OutboundNumber != "" && tr.OutboundNumber != called {
+			continue
+		}
+		if !sipMatchAddress(tr, srcIP) {
+			// The trunk would match, if not for the source address.
+			wrongSource = true
+			continue
+		}
 		if tr.OutboundNumber == "" {
 			// Default/wildcard trunk.
 			defaultTrunk = tr
 			defaultTrunkCnt++
-		} else if tr.OutboundNumber == called {
+		} else {
 			// Trunk specific to the number.
 			if selectedTrunk != nil {
-				return nil, fmt.Errorf("Multiple SIP Trunks matched for %q", called)
+				return nil, fmt.Errorf("Multiple SIP Trunks matched")
 			}
 			selectedTrunk = tr
 			// Keep searching! We want to know if there are any conflicting Trunk definitions.
@@ -167,8 +323,12 @@ func sipMatchTrunk(trunks []*livekit.SIPTrunkInfo, calling, called string) (*liv
 	if selectedTrunk != nil {
 		return selectedTrunk, nil
 	}
+	if wrongSource {
+		// Do not fall back to other default trunks, the call is restricted to other sources.
+		return nil, ErrSIPSourceNotAllowed
+	}
 	if defaultTrunkCnt > 1 {
-		return nil, fmt.Errorf("Multiple default SIP Trunks matched for %q", called)
+		return nil, fmt.Errorf("Multiple default SIP Trunks matched")
 	}
 	// Could still be nil here.
 	return defaultTrunk, nil
@@ -254,19 +414,56 @@ func sipMatchDispatchRule(trunk *livekit.SIPTrunkInfo, rules []*livekit.SIPDispa
 		return best, nil
 	}
 	if trunk == nil {
-		return nil, fmt.Errorf("No SIP Trunk or Dispatch Rules matched for %q", req.CalledNumber)
+		return nil, fmt.Errorf("No SIP Trunk or Dispatch Rules matched")
 	}
-	return nil, fmt.Errorf("No SIP Dispatch Rules matched for %q", req.CalledNumber)
+	return nil, fmt.Errorf("No SIP Dispatch Rules matched")
+}
+
+// sipCallError annotates an error with the numbers of the call it relates to.
+// Numbers are redacted according to the privacy setting, since the error is both logged and returned to the caller.
+func (s *IOInfoService) sipCallError(err error, calling, called string) error {
+	if s.sipConf == nil {
+		return err
+	}
+	if s.sipConf.LogNumbers == config.SIPLogNumbersNone {
+		return err
+	}
+	return fmt.Errorf("%w (from %s, to %s)", err, s.redactSIPNumber(calling), s.redactSIPNumber(called))
+}
+
+// sipCallLogger returns a logger with fields identifying an inbound call. Numbers are redacted according to the privacy setting.
+func (s *IOInfoService) sipCallLogger(sipParticipantID, calling, called string) logger.Logger {
+	values := make([]interface{}, 0, 6)
+	if sipParticipantID != "" {
+		values = append(values, "sipParticipantID", sipParticipantID)
+	}
+	if s.sipConf == nil || s.sipConf.LogNumbers != config.SIPLogNumbersNone {
+		values = append(values, "from", s.redactSIPNumber(calling), "to", s.redactSIPNumber(called))
+	}
+	return logger.GetLogger().WithComponent(sutils.ComponentSIP).WithValues(values...)
+}
+
+func (s *IOInfoService) redactSIPNumber(number string) string {
+	return redactSIPNumber(s.sipConf, number)
 }
 
 // matchSIPTrunk finds a SIP Trunk definition matching the request.
 // Returns nil if no rules matched or an error if there are conflicting definitions.
-func (s *IOInfoService) matchSIPTrunk(ctx context.Context, calling, called string) (*livekit.SIPTrunkInfo, error) {
+func (s *IOInfoService) matchSIPTrunk(ctx context.Context, calling, called, srcAddress string) (*livekit.SIPTrunkInfo, error) {
+	release, err := s.sipBudget.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
 	trunks, err := s.ss.ListSIPTrunk(ctx)
+	release()
 	if err != nil {
 		return nil, err
 	}
-	return sipMatchTrunk(trunks, calling, called)
+	trunk, err := sipMatchTrunk(trunks, calling, called, srcAddress)
+	if err != nil {
+		return nil, s.sipCallError(err, calling, called)
+	}
+	return trunk, nil
 }
 
 // matchSIPDispatchRule finds the best dispatch rule matching the request parameters. Returns an error if no rule matched.
@@ -274,22 +471,62 @@ func (s *IOInfoService) matchSIPTrunk(ctx context.Context, calling, called strin
 func (s *IOInfoService) matchSIPDispatchRule(ctx context.Context, trunk *livekit.SIPTrunkInfo, req *rpc.EvaluateSIPDispatchRulesRequest) (*livekit.SIPDispatchRuleInfo, error) {
 	// Trunk can still be nil here in case none matched or were defined.
 	// This is still fine, but only in case we'll match exactly one wildcard dispatch rule.
+	release, err := s.sipBudget.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
 	rules, err := s.ss.ListSIPDispatchRule(ctx)
+	release()
 	if err != nil {
 		return nil, err
 	}
-	return sipMatchDispatchRule(trunk, rules, req)
+	best, err := sipMatchDispatchRule(trunk, rules, req)
+	if err != nil {
+		s.recordSIPDispatchFailure(ctx, trunk, req, err)
+		return nil, s.sipCallError(err, req.CallingNumber, req.CalledNumber)
+	}
+	s.recordSIPDispatchRuleMatch(best.SipDispatchRuleId)
+	return best, nil
+}
+
+func (s *IOInfoService) recordSIPDispatchFailure(ctx context.Context, trunk *livekit.SIPTrunkInfo, req *rpc.EvaluateSIPDispatchRulesRequest, err error) {
+	trunkID := trunk.GetSipTrunkId()
+	prometheus.SIPDispatchFailed(trunkID)
+	if s.sipConf == nil || s.sipConf.DispatchFailureHistory <= 0 {
+		return
+	}
+	failure := &SIPDispatchFailure{
+		Time:          time.Now(),
+		SIPTrunkID:    trunkID,
+		CallingNumber: s.redactSIPNumber(req.CallingNumber),
+		CalledNumber:  s.redactSIPNumber(req.CalledNumber),
+		Reason:        err.Error(),
+	}
+	if err := s.ss.AppendSIPDispatchFailure(ctx, failure, s.sipConf.DispatchFailureHistory); err != nil {
+		logger.Warnw("could not record SIP dispatch failure", err, "trunkID", trunkID)
+	}
 }
 
 func (s *IOInfoService) EvaluateSIPDispatchRules(ctx context.Context, req *rpc.EvaluateSIPDispatchRulesRequest) (*rpc.EvaluateSIPDispatchRulesResponse, error) {
-	trunk, err := s.matchSIPTrunk(ctx, req.CallingNumber, req.CalledNumber)
+	log := s.sipCallLogger(req.SipParticipantId, req.CallingNumber, req.CalledNumber)
+	if req.SrcAddress != "" {
+		log = log.WithValues("srcAddress", req.SrcAddress)
+	}
+	trunk, err := s.matchSIPTrunk(ctx, req.CallingNumber, req.CalledNumber, req.SrcAddress)
 	if err != nil {
+		log.Infow("SIP dispatch failed", "error", err)
 		return nil, err
 	}
+	if trunk != nil {
+		log = log.WithValues("sipTrunkID", trunk.SipTrunkId)
+	}
+	log.Debugw("matched SIP trunk")
 	best, err := s.matchSIPDispatchRule(ctx, trunk, req)
 	if err != nil {
+		log.Infow("SIP dispatch failed", "error", err)
 		return nil, err
 	}
+	log = log.WithValues("ruleID", best.SipDispatchRuleId)
 	sentPin := req.GetPin()
 
 	from := req.CallingNumber
@@ -302,26 +539,45 @@ func (s *IOInfoService) EvaluateSIPDispatchRules(ctx context.Context, req *rpc.E
 
 	room, rulePin, err := sipGetPinAndRoom(best)
 	if err != nil {
+		log.Infow("SIP dispatch failed", "error", err)
 		return nil, err
 	}
 	if rulePin != "" {
 		if sentPin == "" {
+			log.Debugw("requesting PIN for SIP room")
 			return &rpc.EvaluateSIPDispatchRulesResponse{
 				RequestPin: true,
 			}, nil
 		}
 		if rulePin != sentPin {
 			// This should never happen in practice, because matchSIPDispatchRule should remove rules with the wrong pin.
+			log.Infow("SIP dispatch failed", "error", "incorrect PIN")
 			return nil, fmt.Errorf("Incorrect PIN for SIP room")
 		}
 	} else {
 		// Pin was sent, but room doesn't require one. Assume user accidentally pressed phone button.
 	}
+	logRoom := room
 	switch rule := best.GetRule().GetRule().(type) {
 	case *livekit.SIPDispatchRule_DispatchRuleIndividual:
 		// TODO: Decide on the suffix. Do we need to escape specific characters?
 		room = rule.DispatchRuleIndividual.GetRoomPrefix() + from
+		logRoom = rule.DispatchRuleIndividual.GetRoomPrefix() + s.redactSIPNumber(from)
 	}
+	log = log.WithValues("room", logRoom)
+	if err = sipValidateRoomName(room); err != nil {
+		err = fmt.Errorf("%w in %q", err, logRoom)
+		log.Infow("SIP dispatch failed", "error", err)
+		return nil, err
+	}
+	if s.sipRooms != nil {
+		if err = s.sipRooms.Validate(ctx, livekit.RoomName(room)); err != nil {
+			err = sipRoomError(logRoom, err)
+			log.Infow("SIP dispatch failed", "error", err)
+			return nil, err
+		}
+	}
+	log.Infow("SIP call dispatched")
 	return &rpc.EvaluateSIPDispatchRulesResponse{
 		RoomName:            room,
 		ParticipantIdentity: fromName,
@@ -329,10 +585,21 @@ func (s *IOInfoService) EvaluateSIPDispatchRules(ctx context.Context, req *rpc.E
 }
 
 func (s *IOInfoService) GetSIPTrunkAuthentication(ctx context.Context, req *rpc.GetSIPTrunkAuthenticationRequest) (*rpc.GetSIPTrunkAuthenticationResponse, error) {
-	trunk, err := s.matchSIPTrunk(ctx, req.From, req.To)
+	log := s.sipCallLogger("", req.From, req.To)
+	if req.SrcAddress != "" {
+		log = log.WithValues("srcAddress", req.SrcAddress)
+	}
+	trunk, err := s.matchSIPTrunk(ctx, req.From, req.To, req.SrcAddress)
 	if err != nil {
+		log.Infow("SIP trunk authentication failed", "error", err)
 		return nil, err
 	}
+	if trunk == nil {
+		// no trunk matched, so calls are accepted without authentication, as in EvaluateSIPDispatchRules
+		log.Debugw("no SIP trunk matched for authentication")
+		return &rpc.GetSIPTrunkAuthenticationResponse{}, nil
+	}
+	log.Debugw("matched SIP trunk for authentication", "sipTrunkID", trunk.SipTrunkId)
 	return &rpc.GetSIPTrunkAuthenticationResponse{
 		Username: trunk.Username,
 		Password: trunk.Password,
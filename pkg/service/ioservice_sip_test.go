@@ -1,12 +1,22 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr/funcr"
+	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
 	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
 )
 
 const (
@@ -113,7 +123,7 @@ func TestSIPMatchTrunk(t *testing.T) {
 	for _, c := range cases {
 		c := c
 		t.Run(c.name, func(t *testing.T) {
-			got, err := sipMatchTrunk(c.trunks, sipNumber1, sipNumber2)
+			got, err := sipMatchTrunk(c.trunks, sipNumber1, sipNumber2, "")
 			if c.expErr {
 				require.Error(t, err)
 				require.Nil(t, got)
@@ -130,6 +140,92 @@ func TestSIPMatchTrunk(t *testing.T) {
 	}
 }
 
+func TestSIPMatchTrunkAddress(t *testing.T) {
+	// two carriers delivering the same number range
+	trunks := []*livekit.SIPTrunkInfo{
+		{SipTrunkId: "aaa", OutboundNumber: sipNumber2, InboundAddresses: []string{"10.0.0.0/24"}},
+		{SipTrunkId: "bbb", OutboundNumber: sipNumber2, InboundAddresses: []string{"192.168.1.10", "192.168.1.11"}},
+	}
+	cases := []struct {
+		addr   string
+		exp    int
+		expErr error
+	}{
+		{addr: "10.0.0.5", exp: 0},
+		{addr: "10.0.0.5:5060", exp: 0},
+		{addr: "192.168.1.11", exp: 1},
+		{addr: "192.168.1.11:5060", exp: 1},
+		{addr: "172.16.0.1", expErr: ErrSIPSourceNotAllowed},
+		{addr: "not an ip", expErr: ErrSIPSourceNotAllowed},
+		// unknown source address, neither trunk allows it
+		{addr: "", expErr: ErrSIPSourceNotAllowed},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.addr, func(t *testing.T) {
+			got, err := sipMatchTrunk(trunks, sipNumber1, sipNumber2, c.addr)
+			if c.expErr != nil {
+				require.EqualError(t, err, c.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, trunks[c.exp], got)
+		})
+	}
+
+	// a default trunk does not accept calls for a number restricted to other sources
+	withDefault := append([]*livekit.SIPTrunkInfo{{SipTrunkId: "default"}}, trunks...)
+	_, err := sipMatchTrunk(withDefault, sipNumber1, sipNumber2, "172.16.0.1")
+	require.ErrorIs(t, err, ErrSIPSourceNotAllowed)
+	got, err := sipMatchTrunk(withDefault, sipNumber1, sipNumber3, "172.16.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "default", got.SipTrunkId)
+
+	s := &IOInfoService{ss: &sipTestStore{trunks: trunks}}
+	_, err = s.EvaluateSIPDispatchRules(context.Background(), &rpc.EvaluateSIPDispatchRulesRequest{
+		CallingNumber: sipNumber1,
+		CalledNumber:  sipNumber2,
+		SrcAddress:    "172.16.0.1",
+	})
+	var perr psrpc.Error
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, psrpc.PermissionDenied, perr.Code())
+
+	// a default trunk with an allowlist rejects other and unknown sources
+	restricted := []*livekit.SIPTrunkInfo{{SipTrunkId: "default", InboundAddresses: []string{"10.0.0.0/24"}}}
+	got, err = sipMatchTrunk(restricted, sipNumber1, sipNumber3, "10.0.0.5")
+	require.NoError(t, err)
+	require.Equal(t, "default", got.SipTrunkId)
+	_, err = sipMatchTrunk(restricted, sipNumber1, sipNumber3, "172.16.0.1")
+	require.ErrorIs(t, err, ErrSIPSourceNotAllowed)
+	_, err = sipMatchTrunk(restricted, sipNumber1, sipNumber3, "")
+	require.ErrorIs(t, err, ErrSIPSourceNotAllowed)
+
+	// the number patterns are checked first, a trunk for other callers does not reject the call
+	restricted[0].InboundNumbersRegex = []string{`^\+1`}
+	got, err = sipMatchTrunk(restricted, "+44123", sipNumber3, "172.16.0.1")
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	// trunks without addresses accept any source
+	got, err = sipMatchTrunk([]*livekit.SIPTrunkInfo{{SipTrunkId: "ccc", OutboundNumber: sipNumber2}}, sipNumber1, sipNumber2, "172.16.0.1")
+	require.NoError(t, err)
+	require.Equal(t, "ccc", got.SipTrunkId)
+}
+
+func TestSIPMatchTrunkRegexUpdate(t *testing.T) {
+	trunk := &livekit.SIPTrunkInfo{SipTrunkId: sipTrunkID1, OutboundNumber: sipNumber2, InboundNumbersRegex: []string{"^1111"}}
+	got, err := sipMatchTrunk([]*livekit.SIPTrunkInfo{trunk}, sipNumber1, sipNumber2, "")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	// compiled patterns are cached, the updated set applies to the next call
+	trunk.InboundNumbersRegex = []string{"^3333"}
+	got, err = sipMatchTrunk([]*livekit.SIPTrunkInfo{trunk}, sipNumber1, sipNumber2, "")
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
+
 func newSIPTrunkDispatch() *livekit.SIPTrunkInfo {
 	return &livekit.SIPTrunkInfo{
 		SipTrunkId:     sipTrunkID1,
@@ -388,3 +484,265 @@ func TestSIPMatchDispatchRule(t *testing.T) {
 		})
 	}
 }
+
+type sipTestStore struct {
+	SIPStore
+	trunks   []*livekit.SIPTrunkInfo
+	rules    []*livekit.SIPDispatchRuleInfo
+	matched  []string
+	failures []*SIPDispatchFailure
+}
+
+func (s *sipTestStore) AppendSIPDispatchFailure(ctx context.Context, failure *SIPDispatchFailure, maxFailures int) error {
+	s.failures = append([]*SIPDispatchFailure{failure}, s.failures...)
+	if len(s.failures) > maxFailures {
+		s.failures = s.failures[:maxFailures]
+	}
+	return nil
+}
+
+func (s *sipTestStore) ListSIPDispatchFailures(ctx context.Context) ([]*SIPDispatchFailure, error) {
+	return s.failures, nil
+}
+
+func (s *sipTestStore) ListSIPTrunk(ctx context.Context) ([]*livekit.SIPTrunkInfo, error) {
+	return s.trunks, nil
+}
+
+func (s *sipTestStore) ListSIPDispatchRule(ctx context.Context) ([]*livekit.SIPDispatchRuleInfo, error) {
+	return s.rules, nil
+}
+
+func (s *sipTestStore) RecordSIPDispatchRuleMatch(ctx context.Context, sipDispatchRuleID string, at time.Time) error {
+	s.matched = append(s.matched, sipDispatchRuleID)
+	return nil
+}
+
+func TestSIPTrunkAuthenticationNoTrunk(t *testing.T) {
+	s := &IOInfoService{ss: &sipTestStore{}, sipConf: &config.SIPConfig{}}
+	resp, err := s.GetSIPTrunkAuthentication(context.Background(), &rpc.GetSIPTrunkAuthenticationRequest{
+		From: sipNumber1,
+		To:   sipNumber2,
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Username)
+	require.Empty(t, resp.Password)
+}
+
+func TestSIPRedactNumbers(t *testing.T) {
+	const (
+		calling = "+15551234567"
+		called  = "+15559876543"
+	)
+	cases := []struct {
+		mode config.SIPLogNumbers
+		exp  []string
+	}{
+		{mode: config.SIPLogNumbersFull, exp: []string{calling, called}},
+		{mode: config.SIPLogNumbersLast4, exp: []string{"***4567", "***6543"}},
+		{mode: config.SIPLogNumbersHashed, exp: []string{redactSIPNumber(&config.SIPConfig{LogNumbers: config.SIPLogNumbersHashed, LogNumbersKey: "key"}, calling)}},
+		{mode: config.SIPLogNumbersNone},
+	}
+
+	prev := logger.GetLogger()
+	t.Cleanup(func() { logger.SetLogger(prev, "livekit") })
+
+	for _, c := range cases {
+		c := c
+		t.Run(string(c.mode), func(t *testing.T) {
+			var logs strings.Builder
+			logger.SetLogger(logger.LogRLogger(funcr.New(func(prefix, args string) {
+				logs.WriteString(args)
+				logs.WriteString("\n")
+			}, funcr.Options{})), "livekit")
+
+			stores := map[string]*sipTestStore{
+				"trunk": {trunks: []*livekit.SIPTrunkInfo{
+					{SipTrunkId: sipTrunkID1, OutboundNumber: called},
+					{SipTrunkId: sipTrunkID2, OutboundNumber: called},
+				}},
+				// rules stored before room names were validated, the room name contains the calling number
+				"room": {rules: []*livekit.SIPDispatchRuleInfo{
+					{SipDispatchRuleId: "rule", Rule: newIndividualDispatch("call\t", "")},
+				}},
+			}
+			for name, store := range stores {
+				logs.Reset()
+				s := &IOInfoService{
+					ss:      store,
+					sipConf: &config.SIPConfig{LogNumbers: c.mode, LogNumbersKey: "key"},
+				}
+				_, err := s.EvaluateSIPDispatchRules(context.Background(), &rpc.EvaluateSIPDispatchRulesRequest{
+					CallingNumber: calling,
+					CalledNumber:  called,
+				})
+				require.Error(t, err, name)
+				if name == "room" {
+					require.ErrorIs(t, err, ErrSIPInvalidRoomName)
+				}
+				require.Contains(t, logs.String(), "SIP dispatch failed", name)
+
+				for _, out := range []string{err.Error(), logs.String()} {
+					if name == "trunk" {
+						for _, exp := range c.exp {
+							require.Contains(t, out, exp, name)
+						}
+					}
+					if c.mode != config.SIPLogNumbersFull {
+						require.NotContains(t, out, calling, name)
+						require.NotContains(t, out, called, name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSIPRedactNumberHashKey(t *testing.T) {
+	hashed := func(key string) string {
+		return redactSIPNumber(&config.SIPConfig{LogNumbers: config.SIPLogNumbersHashed, LogNumbersKey: key}, sipNumber1)
+	}
+	require.Equal(t, hashed("a"), hashed("a"))
+	require.NotEqual(t, hashed("a"), hashed("b"))
+	// the number is not hashed without a key
+	require.Equal(t, "<redacted>", hashed(""))
+}
+
+func TestSIPValidateRoomName(t *testing.T) {
+	require.NoError(t, sipValidateRoomName("support"))
+	require.NoError(t, sipValidateRoomName("call-+15551234567"))
+	require.ErrorIs(t, sipValidateRoomName(" support"), ErrSIPInvalidRoomName)
+	require.ErrorIs(t, sipValidateRoomName("sup\nport"), ErrSIPInvalidRoomName)
+	require.ErrorIs(t, sipValidateRoomName("\xff"), ErrSIPInvalidRoomName)
+}
+
+func TestSIPEvaluateRoomErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		exp  error
+	}{
+		{name: "ok"},
+		{name: "room not found", err: ErrRoomNotFound, exp: ErrSIPRoomNotAllowed},
+		{name: "node limit", err: routing.ErrNodeLimitReached, exp: ErrSIPRoomLimitReached},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			store := &sipTestStore{rules: []*livekit.SIPDispatchRuleInfo{
+				{SipDispatchRuleId: "rule", Rule: newDirectDispatch("support", "")},
+			}}
+			s := &IOInfoService{
+				ss:         store,
+				sipMatches: newSIPRuleMatches(),
+				sipRooms: newSIPRoomCache(0, func(ctx context.Context, roomName livekit.RoomName) error {
+					return c.err
+				}),
+			}
+			resp, err := s.EvaluateSIPDispatchRules(context.Background(), &rpc.EvaluateSIPDispatchRulesRequest{
+				CallingNumber: sipNumber1,
+				CalledNumber:  sipNumber2,
+			})
+			if c.exp == nil {
+				require.NoError(t, err)
+				require.Equal(t, "support", resp.RoomName)
+				// matches are written in the background
+				require.Empty(t, store.matched)
+				s.flushSIPDispatchRuleMatches()
+				require.Equal(t, []string{"rule"}, store.matched)
+				return
+			}
+			require.ErrorIs(t, err, c.exp)
+		})
+	}
+}
+
+func TestSIPCallLogger(t *testing.T) {
+	const (
+		calling = "+15551234567"
+		called  = "+15559876543"
+	)
+	prev := logger.GetLogger()
+	t.Cleanup(func() { logger.SetLogger(prev, "livekit") })
+
+	var logs strings.Builder
+	logger.SetLogger(logger.LogRLogger(funcr.New(func(prefix, args string) {
+		logs.WriteString(args)
+		logs.WriteString("\n")
+	}, funcr.Options{})), "livekit")
+
+	s := &IOInfoService{
+		ss: &sipTestStore{
+			trunks: []*livekit.SIPTrunkInfo{{SipTrunkId: sipTrunkID1, OutboundNumber: called}},
+			rules: []*livekit.SIPDispatchRuleInfo{{
+				SipDispatchRuleId: "rule",
+				Rule: &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleIndividual{
+					DispatchRuleIndividual: &livekit.SIPDispatchRuleIndividual{RoomPrefix: "call-"},
+				}},
+			}},
+		},
+		sipConf: &config.SIPConfig{LogNumbers: config.SIPLogNumbersLast4},
+	}
+	resp, err := s.EvaluateSIPDispatchRules(context.Background(), &rpc.EvaluateSIPDispatchRulesRequest{
+		SipParticipantId: "SCL_aaa",
+		CallingNumber:    calling,
+		CalledNumber:     called,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "call-"+calling, resp.RoomName)
+
+	out := logs.String()
+	require.Contains(t, out, "SIP call dispatched")
+	require.Contains(t, out, `"sipParticipantID"="SCL_aaa"`)
+	require.Contains(t, out, `"sipTrunkID"="`+sipTrunkID1+`"`)
+	require.Contains(t, out, `"ruleID"="rule"`)
+	require.Contains(t, out, `"room"="call-***4567"`)
+	require.NotContains(t, out, calling)
+	require.NotContains(t, out, called)
+}
+
+func TestSIPDispatchFailures(t *testing.T) {
+	s := &IOInfoService{
+		ss: &sipTestStore{
+			trunks: []*livekit.SIPTrunkInfo{{SipTrunkId: sipTrunkID1, OutboundNumber: sipNumber2}},
+			rules: []*livekit.SIPDispatchRuleInfo{
+				{SipDispatchRuleId: "rule", TrunkIds: []string{sipTrunkID2}, Rule: newDirectDispatch("support", "")},
+			},
+		},
+		sipConf: &config.SIPConfig{LogNumbers: config.SIPLogNumbersLast4, DispatchFailureHistory: 2},
+	}
+	for _, calling := range []string{"+15550000001", "+15550000002", "+15550000003"} {
+		_, err := s.EvaluateSIPDispatchRules(context.Background(), &rpc.EvaluateSIPDispatchRulesRequest{
+			CallingNumber: calling,
+			CalledNumber:  sipNumber2,
+		})
+		require.Error(t, err)
+	}
+
+	_, err := s.ListSIPDispatchFailures(context.Background())
+	require.Error(t, err)
+
+	ctx := WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{RoomCreate: true}})
+	failures, err := s.ListSIPDispatchFailures(ctx)
+	require.NoError(t, err)
+	require.Len(t, failures, 2)
+	require.Equal(t, "***0003", failures[0].CallingNumber)
+	require.Equal(t, "***0002", failures[1].CallingNumber)
+	for _, f := range failures {
+		require.Equal(t, sipTrunkID1, f.SIPTrunkID)
+		require.Equal(t, redactSIPNumber(&config.SIPConfig{LogNumbers: config.SIPLogNumbersLast4}, sipNumber2), f.CalledNumber)
+		require.NotEmpty(t, f.Reason)
+	}
+
+	// history is disabled
+	s.sipConf.DispatchFailureHistory = 0
+	_, err = s.EvaluateSIPDispatchRules(context.Background(), &rpc.EvaluateSIPDispatchRulesRequest{
+		CallingNumber: "+15550000004",
+		CalledNumber:  sipNumber2,
+	})
+	require.Error(t, err)
+	failures, err = s.ListSIPDispatchFailures(ctx)
+	require.NoError(t, err)
+	require.Empty(t, failures)
+	require.Len(t, s.ss.(*sipTestStore).failures, 2)
+}
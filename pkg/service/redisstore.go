@@ -16,6 +16,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -51,9 +52,27 @@ const (
 	IngressStatePrefix = "{ingress}_state:"
 	RoomIngressPrefix  = "room_{ingress}:"
 
-	SIPTrunkKey        = "sip_trunk"
-	SIPDispatchRuleKey = "sip_dispatch_rule"
-	SIPParticipantKey  = "sip_participant"
+	// SIP keys read and written together by RunSIPTxn share the {sip} hash tag, so that they map to a single
+	// Redis Cluster slot
+	SIPTrunkKey        = "{sip}_trunk"
+	SIPDispatchRuleKey = "{sip}_dispatch_rule"
+	SIPParticipantKey  = "{sip}_participant"
+
+	// SIPParticipantEventsPrefix is a list of JSON encoded events for a SIP participant
+	SIPParticipantEventsPrefix = "sip_participant_events:"
+	// SIPDispatchFailuresKey is a list of JSON encoded SIPDispatchFailure, newest first
+	SIPDispatchFailuresKey = "sip_dispatch_failures"
+	// SIPDispatchRuleMatchedKey, SIPDispatchRuleRoomMissingKey and SIPDispatchRuleFirstSeenKey are hashes of
+	// dispatch rule ID => unix time in ms
+	SIPDispatchRuleMatchedKey     = "{sip}_dispatch_rule_matched"
+	SIPDispatchRuleRoomMissingKey = "{sip}_dispatch_rule_room_missing"
+	SIPDispatchRuleFirstSeenKey   = "{sip}_dispatch_rule_first_seen"
+
+	// SIPScheduledCallKey is a hash of call ID => JSON encoded SIPScheduledCall. SIPScheduledCallTimesKey is a sorted
+	// set of pending call IDs by scheduled time, SIPScheduledCallLeasesKey of claimed call IDs by lease expiry
+	SIPScheduledCallKey       = "{sip_scheduled}_call"
+	SIPScheduledCallTimesKey  = "{sip_scheduled}_times"
+	SIPScheduledCallLeasesKey = "{sip_scheduled}_leases"
 
 	// RoomParticipantsPrefix is hash of participant_name => ParticipantInfo
 	RoomParticipantsPrefix = "room_participants:"
@@ -65,10 +84,12 @@ const (
 )
 
 type RedisStore struct {
-	rc           redis.UniversalClient
-	unlockScript *redis.Script
-	ctx          context.Context
-	done         chan struct{}
+	rc                  redis.UniversalClient
+	unlockScript        *redis.Script
+	claimSIPCallsScript *redis.Script
+	cancelSIPCallScript *redis.Script
+	ctx                 context.Context
+	done                chan struct{}
 }
 
 func NewRedisStore(rc redis.UniversalClient) *RedisStore {
@@ -77,10 +98,35 @@ func NewRedisStore(rc redis.UniversalClient) *RedisStore {
 					 else return 0
 					 end`
 
+	// KEYS: times, leases. ARGV: now, lease expiry, limit
+	claimSIPCallsScript := `local ids = redis.call("zrangebyscore", KEYS[1], "-inf", ARGV[1], "limit", 0, ARGV[3])
+					 for _, id in ipairs(ids) do
+						redis.call("zrem", KEYS[1], id)
+					 end
+					 local left = tonumber(ARGV[3]) - #ids
+					 if left > 0 then
+						local expired = redis.call("zrangebyscore", KEYS[2], "-inf", ARGV[1], "limit", 0, left)
+						for _, id in ipairs(expired) do
+							table.insert(ids, id)
+						end
+					 end
+					 for _, id in ipairs(ids) do
+						redis.call("zadd", KEYS[2], ARGV[2], id)
+					 end
+					 return ids`
+
+	// KEYS: times, calls. ARGV: call ID
+	cancelSIPCallScript := `if redis.call("zrem", KEYS[1], ARGV[1]) == 1 then
+						return redis.call("hdel", KEYS[2], ARGV[1])
+					 else return 0
+					 end`
+
 	return &RedisStore{
-		ctx:          context.Background(),
-		rc:           rc,
-		unlockScript: redis.NewScript(unlockScript),
+		ctx:                 context.Background(),
+		rc:                  rc,
+		unlockScript:        redis.NewScript(unlockScript),
+		claimSIPCallsScript: redis.NewScript(claimSIPCallsScript),
+		cancelSIPCallScript: redis.NewScript(cancelSIPCallScript),
 	}
 }
 
@@ -106,10 +152,53 @@ func (s *RedisStore) Start() error {
 		}
 	}
 
+	if err = s.migrateSIPKeys(); err != nil {
+		return err
+	}
+
 	go s.egressWorker()
 	return nil
 }
 
+// legacySIPKeys maps the SIP hashes stored before the {sip} hash tag was added to their current keys.
+var legacySIPKeys = map[string]string{
+	"sip_trunk":                      SIPTrunkKey,
+	"sip_dispatch_rule":              SIPDispatchRuleKey,
+	"sip_participant":                SIPParticipantKey,
+	"sip_dispatch_rule_matched":      SIPDispatchRuleMatchedKey,
+	"sip_dispatch_rule_room_missing": SIPDispatchRuleRoomMissingKey,
+}
+
+// migrateSIPKeys moves entries from the legacy SIP hashes. RENAME does not work across cluster slots, so entries
+// are copied without overwriting newer ones and only the copied fields are removed. Entries written by nodes
+// still on the old keys during a rolling upgrade are picked up by the next start.
+func (s *RedisStore) migrateSIPKeys() error {
+	for oldKey, newKey := range legacySIPKeys {
+		entries, err := s.rc.HGetAll(s.ctx, oldKey).Result()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		logger.Infow("migrating SIP store key", "from", oldKey, "to", newKey, "entries", len(entries))
+
+		pp := s.rc.Pipeline()
+		fields := make([]string, 0, len(entries))
+		for field, value := range entries {
+			pp.HSetNX(s.ctx, newKey, field, value)
+			fields = append(fields, field)
+		}
+		if _, err = pp.Exec(s.ctx); err != nil {
+			return errors.Wrap(err, "could not migrate SIP store key")
+		}
+		if err = s.rc.HDel(s.ctx, oldKey, fields...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *RedisStore) Stop() {
 	select {
 	case <-s.done:
@@ -818,7 +907,11 @@ func (s *RedisStore) DeleteIngress(_ context.Context, info *livekit.IngressInfo)
 }
 
 func (s *RedisStore) loadOne(ctx context.Context, key, id string, info proto.Message, notFoundErr error) error {
-	data, err := s.rc.HGet(s.ctx, key, id).Result()
+	return s.loadOneFrom(s.rc, key, id, info, notFoundErr)
+}
+
+func (s *RedisStore) loadOneFrom(c redis.Cmdable, key, id string, info proto.Message, notFoundErr error) error {
+	data, err := c.HGet(s.ctx, key, id).Result()
 	switch err {
 	case nil:
 		return proto.Unmarshal([]byte(data), info)
@@ -830,7 +923,11 @@ func (s *RedisStore) loadOne(ctx context.Context, key, id string, info proto.Mes
 }
 
 func (s *RedisStore) loadMany(ctx context.Context, key string, onResult func() proto.Message) error {
-	data, err := s.rc.HGetAll(s.ctx, key).Result()
+	return s.loadManyFrom(s.rc, key, onResult)
+}
+
+func (s *RedisStore) loadManyFrom(c redis.Cmdable, key string, onResult func() proto.Message) error {
+	data, err := c.HGetAll(s.ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return nil
@@ -897,7 +994,13 @@ func (s *RedisStore) LoadSIPDispatchRule(ctx context.Context, sipDispatchRuleId
 }
 
 func (s *RedisStore) DeleteSIPDispatchRule(ctx context.Context, info *livekit.SIPDispatchRuleInfo) error {
-	return s.rc.HDel(s.ctx, SIPDispatchRuleKey, info.SipDispatchRuleId).Err()
+	tx := s.rc.TxPipeline()
+	tx.HDel(s.ctx, SIPDispatchRuleKey, info.SipDispatchRuleId)
+	tx.HDel(s.ctx, SIPDispatchRuleMatchedKey, info.SipDispatchRuleId)
+	tx.HDel(s.ctx, SIPDispatchRuleRoomMissingKey, info.SipDispatchRuleId)
+	tx.HDel(s.ctx, SIPDispatchRuleFirstSeenKey, info.SipDispatchRuleId)
+	_, err := tx.Exec(s.ctx)
+	return err
 }
 
 func (s *RedisStore) ListSIPDispatchRule(ctx context.Context) (infos []*livekit.SIPDispatchRuleInfo, err error) {
@@ -926,6 +1029,30 @@ func (s *RedisStore) LoadSIPParticipant(ctx context.Context, sipParticipantId st
 	return info, nil
 }
 
+func (s *RedisStore) LoadSIPParticipants(ctx context.Context, sipParticipantIds []string) ([]*livekit.SIPParticipantInfo, error) {
+	if len(sipParticipantIds) == 0 {
+		return nil, nil
+	}
+	results, err := s.rc.HMGet(s.ctx, SIPParticipantKey, sipParticipantIds...).Result()
+	if err != nil && err != redis.Nil {
+		return nil, errors.Wrap(err, "could not get sip participants")
+	}
+
+	infos := make([]*livekit.SIPParticipantInfo, 0, len(results))
+	for _, r := range results {
+		data, ok := r.(string)
+		if !ok {
+			continue
+		}
+		info := &livekit.SIPParticipantInfo{}
+		if err = proto.Unmarshal([]byte(data), info); err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
 func (s *RedisStore) DeleteSIPParticipant(ctx context.Context, info *livekit.SIPParticipantInfo) error {
 	return s.rc.HDel(s.ctx, SIPParticipantKey, info.SipParticipantId).Err()
 }
@@ -942,3 +1069,376 @@ func (s *RedisStore) ListSIPParticipant(ctx context.Context) (infos []*livekit.S
 func (s *RedisStore) SendSIPParticipantDTMF(ctx context.Context, info *livekit.SendSIPParticipantDTMFRequest) (*livekit.SIPParticipantDTMFInfo, error) {
 	return nil, fmt.Errorf("TODO")
 }
+
+func (s *RedisStore) AppendSIPParticipantEvent(ctx context.Context, sipParticipantId string, event *SIPParticipantEvent, maxEvents int, ttl time.Duration) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	key := SIPParticipantEventsPrefix + sipParticipantId
+	tx := s.rc.TxPipeline()
+	tx.RPush(s.ctx, key, data)
+	if maxEvents > 0 {
+		tx.LTrim(s.ctx, key, -int64(maxEvents), -1)
+	}
+	if ttl > 0 {
+		tx.Expire(s.ctx, key, ttl)
+	}
+	if _, err = tx.Exec(s.ctx); err != nil {
+		return errors.Wrap(err, "could not store sip participant event")
+	}
+	return nil
+}
+
+func (s *RedisStore) ListSIPParticipantEvents(ctx context.Context, sipParticipantId string) ([]*SIPParticipantEvent, error) {
+	data, err := s.rc.LRange(s.ctx, SIPParticipantEventsPrefix+sipParticipantId, 0, -1).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	events := make([]*SIPParticipantEvent, 0, len(data))
+	for _, d := range data {
+		event := &SIPParticipantEvent{}
+		if err = json.Unmarshal([]byte(d), event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (s *RedisStore) AppendSIPDispatchFailure(ctx context.Context, failure *SIPDispatchFailure, maxFailures int) error {
+	data, err := json.Marshal(failure)
+	if err != nil {
+		return err
+	}
+
+	tx := s.rc.TxPipeline()
+	tx.LPush(s.ctx, SIPDispatchFailuresKey, data)
+	tx.LTrim(s.ctx, SIPDispatchFailuresKey, 0, int64(maxFailures)-1)
+	if _, err = tx.Exec(s.ctx); err != nil {
+		return errors.Wrap(err, "could not store sip dispatch failure")
+	}
+	return nil
+}
+
+func (s *RedisStore) ListSIPDispatchFailures(ctx context.Context) ([]*SIPDispatchFailure, error) {
+	data, err := s.rc.LRange(s.ctx, SIPDispatchFailuresKey, 0, -1).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	failures := make([]*SIPDispatchFailure, 0, len(data))
+	for _, d := range data {
+		failure := &SIPDispatchFailure{}
+		if err = json.Unmarshal([]byte(d), failure); err != nil {
+			return nil, err
+		}
+		failures = append(failures, failure)
+	}
+	return failures, nil
+}
+
+func (s *RedisStore) RecordSIPDispatchRuleMatch(ctx context.Context, sipDispatchRuleId string, at time.Time) error {
+	return s.rc.HSet(s.ctx, SIPDispatchRuleMatchedKey, sipDispatchRuleId, at.UnixMilli()).Err()
+}
+
+func (s *RedisStore) SetSIPDispatchRuleRoomMissing(ctx context.Context, sipDispatchRuleId string, since time.Time) error {
+	if since.IsZero() {
+		return s.rc.HDel(s.ctx, SIPDispatchRuleRoomMissingKey, sipDispatchRuleId).Err()
+	}
+	return s.rc.HSet(s.ctx, SIPDispatchRuleRoomMissingKey, sipDispatchRuleId, since.UnixMilli()).Err()
+}
+
+func (s *RedisStore) MarkSIPDispatchRulesSeen(ctx context.Context, sipDispatchRuleIds []string, at time.Time) error {
+	if len(sipDispatchRuleIds) == 0 {
+		return nil
+	}
+	pp := s.rc.Pipeline()
+	for _, id := range sipDispatchRuleIds {
+		pp.HSetNX(s.ctx, SIPDispatchRuleFirstSeenKey, id, at.UnixMilli())
+	}
+	_, err := pp.Exec(s.ctx)
+	return err
+}
+
+func (s *RedisStore) LoadSIPDispatchRuleActivity(ctx context.Context) (map[string]*SIPDispatchRuleActivity, error) {
+	tx := s.rc.TxPipeline()
+	matched := tx.HGetAll(s.ctx, SIPDispatchRuleMatchedKey)
+	missing := tx.HGetAll(s.ctx, SIPDispatchRuleRoomMissingKey)
+	seen := tx.HGetAll(s.ctx, SIPDispatchRuleFirstSeenKey)
+	if _, err := tx.Exec(s.ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	activity := make(map[string]*SIPDispatchRuleActivity)
+	get := func(id string) *SIPDispatchRuleActivity {
+		a := activity[id]
+		if a == nil {
+			a = &SIPDispatchRuleActivity{}
+			activity[id] = a
+		}
+		return a
+	}
+	for id, v := range matched.Val() {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		get(id).LastMatched = time.UnixMilli(ms)
+	}
+	for id, v := range missing.Val() {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		get(id).RoomMissingSince = time.UnixMilli(ms)
+	}
+	for id, v := range seen.Val() {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		get(id).FirstSeen = time.UnixMilli(ms)
+	}
+	return activity, nil
+}
+
+func (s *RedisStore) StoreSIPScheduledCall(ctx context.Context, call *SIPScheduledCall) error {
+	data, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+
+	tx := s.rc.TxPipeline()
+	tx.HSet(s.ctx, SIPScheduledCallKey, call.ID, data)
+	tx.ZAdd(s.ctx, SIPScheduledCallTimesKey, redis.Z{Score: float64(call.ScheduledAt.UnixMilli()), Member: call.ID})
+	_, err = tx.Exec(s.ctx)
+	return err
+}
+
+func (s *RedisStore) ListSIPScheduledCalls(ctx context.Context) ([]*SIPScheduledCall, error) {
+	data, err := s.rc.HVals(s.ctx, SIPScheduledCallKey).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	calls := make([]*SIPScheduledCall, 0, len(data))
+	for _, d := range data {
+		call := &SIPScheduledCall{}
+		if err = json.Unmarshal([]byte(d), call); err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+func (s *RedisStore) ClaimSIPScheduledCalls(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*SIPScheduledCall, error) {
+	ids, err := s.claimSIPCallsScript.Run(s.ctx, s.rc,
+		[]string{SIPScheduledCallTimesKey, SIPScheduledCallLeasesKey},
+		now.UnixMilli(), leaseUntil.UnixMilli(), limit,
+	).StringSlice()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	data, err := s.rc.HMGet(s.ctx, SIPScheduledCallKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	calls := make([]*SIPScheduledCall, 0, len(data))
+	for i, d := range data {
+		str, ok := d.(string)
+		if !ok {
+			// completed by the server whose lease had expired
+			_ = s.rc.ZRem(s.ctx, SIPScheduledCallLeasesKey, ids[i]).Err()
+			continue
+		}
+		call := &SIPScheduledCall{}
+		if err = json.Unmarshal([]byte(str), call); err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+func (s *RedisStore) CompleteSIPScheduledCall(ctx context.Context, id string) error {
+	tx := s.rc.TxPipeline()
+	tx.ZRem(s.ctx, SIPScheduledCallLeasesKey, id)
+	tx.HDel(s.ctx, SIPScheduledCallKey, id)
+	_, err := tx.Exec(s.ctx)
+	return err
+}
+
+func (s *RedisStore) CancelSIPScheduledCall(ctx context.Context, id string) error {
+	res, err := s.cancelSIPCallScript.Run(s.ctx, s.rc, []string{SIPScheduledCallTimesKey, SIPScheduledCallKey}, id).Int()
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return ErrSIPScheduledCallMissing
+	}
+	return nil
+}
+
+func (s *RedisStore) RunSIPTxn(ctx context.Context, fn func(tx SIPTxn) error) error {
+	txf := func(tx *redis.Tx) error {
+		sipTx := &redisSIPTxn{s: s, tx: tx}
+		if err := fn(sipTx); err != nil {
+			return err
+		}
+
+		// go-redis skips EXEC for an empty pipeline, so a PING is always queued. EXEC then fails if a watched key
+		// changed, and read-only transactions that saw a partial update are retried too.
+		results, err := tx.TxPipelined(s.ctx, func(p redis.Pipeliner) error {
+			for _, op := range sipTx.ops {
+				op(p)
+			}
+			p.Ping(s.ctx)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, res := range results {
+			if err := res.Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Retry if the keys have been changed.
+	for i := 0; i < maxRetries; i++ {
+		err := s.rc.Watch(s.ctx, txf, SIPTrunkKey, SIPDispatchRuleKey)
+		switch err {
+		case redis.TxFailedErr:
+			// Optimistic lock lost. Retry.
+			continue
+		default:
+			return err
+		}
+	}
+	return ErrSIPStoreConflict
+}
+
+// redisSIPTxn reads through the watched connection and queues writes until the transaction is executed.
+type redisSIPTxn struct {
+	s   *RedisStore
+	tx  *redis.Tx
+	ops []func(p redis.Pipeliner)
+
+	watchingParticipants bool
+}
+
+func (t *redisSIPTxn) hset(key, id string, info proto.Message) error {
+	data, err := proto.Marshal(info)
+	if err != nil {
+		return err
+	}
+	t.ops = append(t.ops, func(p redis.Pipeliner) {
+		p.HSet(t.s.ctx, key, id, data)
+	})
+	return nil
+}
+
+func (t *redisSIPTxn) hdel(key, id string) {
+	t.ops = append(t.ops, func(p redis.Pipeliner) {
+		p.HDel(t.s.ctx, key, id)
+	})
+}
+
+func (t *redisSIPTxn) StoreSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error {
+	return t.hset(SIPTrunkKey, info.SipTrunkId, info)
+}
+
+func (t *redisSIPTxn) LoadSIPTrunk(ctx context.Context, sipTrunkId string) (*livekit.SIPTrunkInfo, error) {
+	info := &livekit.SIPTrunkInfo{}
+	if err := t.s.loadOneFrom(t.tx, SIPTrunkKey, sipTrunkId, info, ErrSIPTrunkNotFound); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (t *redisSIPTxn) ListSIPTrunk(ctx context.Context) (infos []*livekit.SIPTrunkInfo, err error) {
+	err = t.s.loadManyFrom(t.tx, SIPTrunkKey, func() proto.Message {
+		infos = append(infos, &livekit.SIPTrunkInfo{})
+		return infos[len(infos)-1]
+	})
+	return infos, err
+}
+
+func (t *redisSIPTxn) DeleteSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error {
+	t.hdel(SIPTrunkKey, info.SipTrunkId)
+	return nil
+}
+
+func (t *redisSIPTxn) StoreSIPDispatchRule(ctx context.Context, info *livekit.SIPDispatchRuleInfo) error {
+	return t.hset(SIPDispatchRuleKey, info.SipDispatchRuleId, info)
+}
+
+func (t *redisSIPTxn) LoadSIPDispatchRule(ctx context.Context, sipDispatchRuleId string) (*livekit.SIPDispatchRuleInfo, error) {
+	info := &livekit.SIPDispatchRuleInfo{}
+	if err := t.s.loadOneFrom(t.tx, SIPDispatchRuleKey, sipDispatchRuleId, info, ErrSIPDispatchRuleNotFound); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (t *redisSIPTxn) ListSIPDispatchRule(ctx context.Context) (infos []*livekit.SIPDispatchRuleInfo, err error) {
+	err = t.s.loadManyFrom(t.tx, SIPDispatchRuleKey, func() proto.Message {
+		infos = append(infos, &livekit.SIPDispatchRuleInfo{})
+		return infos[len(infos)-1]
+	})
+	return infos, err
+}
+
+func (t *redisSIPTxn) DeleteSIPDispatchRule(ctx context.Context, info *livekit.SIPDispatchRuleInfo) error {
+	t.hdel(SIPDispatchRuleKey, info.SipDispatchRuleId)
+	t.hdel(SIPDispatchRuleMatchedKey, info.SipDispatchRuleId)
+	t.hdel(SIPDispatchRuleRoomMissingKey, info.SipDispatchRuleId)
+	t.hdel(SIPDispatchRuleFirstSeenKey, info.SipDispatchRuleId)
+	return nil
+}
+
+func (t *redisSIPTxn) StoreSIPParticipant(ctx context.Context, info *livekit.SIPParticipantInfo) error {
+	return t.hset(SIPParticipantKey, info.SipParticipantId, info)
+}
+
+// watchParticipants adds participants to the watched keys, only for transactions that read them, so that
+// participants joining and leaving do not conflict with trunk and rule changes.
+func (t *redisSIPTxn) watchParticipants() error {
+	if t.watchingParticipants {
+		return nil
+	}
+	if err := t.tx.Watch(t.s.ctx, SIPParticipantKey).Err(); err != nil {
+		return err
+	}
+	t.watchingParticipants = true
+	return nil
+}
+
+func (t *redisSIPTxn) LoadSIPParticipant(ctx context.Context, sipParticipantId string) (*livekit.SIPParticipantInfo, error) {
+	if err := t.watchParticipants(); err != nil {
+		return nil, err
+	}
+	info := &livekit.SIPParticipantInfo{}
+	if err := t.s.loadOneFrom(t.tx, SIPParticipantKey, sipParticipantId, info, ErrSIPParticipantNotFound); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (t *redisSIPTxn) ListSIPParticipant(ctx context.Context) (infos []*livekit.SIPParticipantInfo, err error) {
+	if err = t.watchParticipants(); err != nil {
+		return nil, err
+	}
+	err = t.s.loadManyFrom(t.tx, SIPParticipantKey, func() proto.Message {
+		infos = append(infos, &livekit.SIPParticipantInfo{})
+		return infos[len(infos)-1]
+	})
+	return infos, err
+}
@@ -16,12 +16,17 @@ package service_test
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/livekit/protocol/ingress"
 	"github.com/livekit/protocol/livekit"
@@ -294,3 +299,121 @@ func compareIngressInfo(t *testing.T, expected, v *livekit.IngressInfo) {
 	require.Equal(t, expected.StreamKey, v.StreamKey)
 	require.Equal(t, expected.RoomName, v.RoomName)
 }
+
+func TestSIPStoreTxn(t *testing.T) {
+	testSIPStoreTxn(t, redisClient())
+}
+
+func TestSIPStoreTxnCluster(t *testing.T) {
+	// all slots are served by the local node, but the cluster client still rejects transactions across slots
+	rc := redis.NewClusterClient(&redis.ClusterOptions{
+		ClusterSlots: func(ctx context.Context) ([]redis.ClusterSlot, error) {
+			return []redis.ClusterSlot{{
+				Start: 0,
+				End:   16383,
+				Nodes: []redis.ClusterNode{{Addr: "localhost:6379"}},
+			}}, nil
+		},
+	})
+	t.Cleanup(func() { _ = rc.Close() })
+	testSIPStoreTxn(t, rc)
+
+	// participant reads add the participant key to the watched keys
+	ctx := context.Background()
+	rs := service.NewRedisStore(rc)
+	err := rs.RunSIPTxn(ctx, func(tx service.SIPTxn) error {
+		_, err := tx.ListSIPParticipant(ctx)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestSIPStoreMigrateKeys(t *testing.T) {
+	ctx := context.Background()
+	rc := redisClient()
+	rs := service.NewRedisStore(rc)
+
+	trunk := &livekit.SIPTrunkInfo{SipTrunkId: "ST_legacy"}
+	data, err := proto.Marshal(trunk)
+	require.NoError(t, err)
+	require.NoError(t, rc.HSet(ctx, "sip_trunk", trunk.SipTrunkId, data).Err())
+	t.Cleanup(func() {
+		_ = rs.DeleteSIPTrunk(ctx, trunk)
+	})
+
+	require.NoError(t, rs.Start())
+	t.Cleanup(rs.Stop)
+
+	_, err = rs.LoadSIPTrunk(ctx, trunk.SipTrunkId)
+	require.NoError(t, err)
+	n, err := rc.Exists(ctx, "sip_trunk").Result()
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func testSIPStoreTxn(t *testing.T, rc redis.UniversalClient) {
+	ctx := context.Background()
+	rs := service.NewRedisStore(rc)
+
+	trunk := &livekit.SIPTrunkInfo{SipTrunkId: "ST_txn"}
+	rule := &livekit.SIPDispatchRuleInfo{SipDispatchRuleId: "SDR_txn"}
+	t.Cleanup(func() {
+		_ = rs.DeleteSIPTrunk(ctx, trunk)
+		_ = rs.DeleteSIPDispatchRule(ctx, rule)
+	})
+
+	// the writer keeps the trunk number and the rule's trunk ID in sync
+	var wg sync.WaitGroup
+	wg.Add(1)
+	// the writer must finish before the test does, even when the reader fails
+	defer wg.Wait()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			num := strconv.Itoa(i)
+			err := rs.RunSIPTxn(ctx, func(tx service.SIPTxn) error {
+				if err := tx.StoreSIPTrunk(ctx, &livekit.SIPTrunkInfo{SipTrunkId: trunk.SipTrunkId, OutboundNumber: num}); err != nil {
+					return err
+				}
+				return tx.StoreSIPDispatchRule(ctx, &livekit.SIPDispatchRuleInfo{SipDispatchRuleId: rule.SipDispatchRuleId, TrunkIds: []string{num}})
+			})
+			assert.NoError(t, err)
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		var num, ruleNum string
+		err := rs.RunSIPTxn(ctx, func(tx service.SIPTxn) error {
+			num, ruleNum = "", ""
+			tr, err := tx.LoadSIPTrunk(ctx, trunk.SipTrunkId)
+			if err == service.ErrSIPTrunkNotFound {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			r, err := tx.LoadSIPDispatchRule(ctx, rule.SipDispatchRuleId)
+			if err != nil {
+				return err
+			}
+			num, ruleNum = tr.OutboundNumber, r.TrunkIds[0]
+			return nil
+		})
+		if err == service.ErrSIPStoreConflict {
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, num, ruleNum)
+	}
+	wg.Wait()
+
+	// changes are discarded when the transaction fails
+	err := rs.RunSIPTxn(ctx, func(tx service.SIPTxn) error {
+		if err := tx.DeleteSIPTrunk(ctx, trunk); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	require.Error(t, err)
+	_, err = rs.LoadSIPTrunk(ctx, trunk.SipTrunkId)
+	require.NoError(t, err)
+}
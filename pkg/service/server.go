@@ -47,6 +47,7 @@ import (
 type LivekitServer struct {
 	config       *config.Config
 	ioService    *IOInfoService
+	sipService   *SIPService
 	rtcService   *RTCService
 	agentService *AgentService
 	httpServer   *http.Server
@@ -79,6 +80,7 @@ func NewLivekitServer(conf *config.Config,
 	s = &LivekitServer{
 		config:       conf,
 		ioService:    ioService,
+		sipService:   sipService,
 		rtcService:   rtcService,
 		agentService: agentService,
 		router:       router,
@@ -130,6 +132,8 @@ func NewLivekitServer(conf *config.Config,
 	mux.Handle(egressServer.PathPrefix(), egressServer)
 	mux.Handle(ingressServer.PathPrefix(), ingressServer)
 	mux.Handle(sipServer.PathPrefix(), sipServer)
+	mux.HandleFunc("/sip/status", sipService.ServeStatus)
+	mux.Handle("/sip/", NewSIPHTTPHandler(sipService, ioService))
 	mux.Handle("/rtc", rtcService)
 	mux.Handle("/agent", agentService)
 	mux.HandleFunc("/rtc/validate", rtcService.Validate)
@@ -190,6 +194,7 @@ func (s *LivekitServer) Start() error {
 	if err := s.ioService.Start(); err != nil {
 		return err
 	}
+	s.sipService.Start()
 
 	addresses := s.config.BindAddresses
 	if addresses == nil {
@@ -288,6 +293,7 @@ func (s *LivekitServer) Start() error {
 	s.roomManager.Stop()
 	s.signalServer.Stop()
 	s.ioService.Stop()
+	s.sipService.Stop()
 
 	close(s.closedChan)
 	return nil
@@ -0,0 +1,2114 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package servicefakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/protocol/livekit"
+)
+
+type FakeSIPStore struct {
+	AppendSIPDispatchFailureStub        func(context.Context, *service.SIPDispatchFailure, int) error
+	appendSIPDispatchFailureMutex       sync.RWMutex
+	appendSIPDispatchFailureArgsForCall []struct {
+		arg1 context.Context
+		arg2 *service.SIPDispatchFailure
+		arg3 int
+	}
+	appendSIPDispatchFailureReturns struct {
+		result1 error
+	}
+	appendSIPDispatchFailureReturnsOnCall map[int]struct {
+		result1 error
+	}
+	AppendSIPParticipantEventStub        func(context.Context, string, *service.SIPParticipantEvent, int, time.Duration) error
+	appendSIPParticipantEventMutex       sync.RWMutex
+	appendSIPParticipantEventArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *service.SIPParticipantEvent
+		arg4 int
+		arg5 time.Duration
+	}
+	appendSIPParticipantEventReturns struct {
+		result1 error
+	}
+	appendSIPParticipantEventReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CancelSIPScheduledCallStub        func(context.Context, string) error
+	cancelSIPScheduledCallMutex       sync.RWMutex
+	cancelSIPScheduledCallArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	cancelSIPScheduledCallReturns struct {
+		result1 error
+	}
+	cancelSIPScheduledCallReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ClaimSIPScheduledCallsStub        func(context.Context, time.Time, time.Time, int) ([]*service.SIPScheduledCall, error)
+	claimSIPScheduledCallsMutex       sync.RWMutex
+	claimSIPScheduledCallsArgsForCall []struct {
+		arg1 context.Context
+		arg2 time.Time
+		arg3 time.Time
+		arg4 int
+	}
+	claimSIPScheduledCallsReturns struct {
+		result1 []*service.SIPScheduledCall
+		result2 error
+	}
+	claimSIPScheduledCallsReturnsOnCall map[int]struct {
+		result1 []*service.SIPScheduledCall
+		result2 error
+	}
+	CompleteSIPScheduledCallStub        func(context.Context, string) error
+	completeSIPScheduledCallMutex       sync.RWMutex
+	completeSIPScheduledCallArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	completeSIPScheduledCallReturns struct {
+		result1 error
+	}
+	completeSIPScheduledCallReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteSIPDispatchRuleStub        func(context.Context, *livekit.SIPDispatchRuleInfo) error
+	deleteSIPDispatchRuleMutex       sync.RWMutex
+	deleteSIPDispatchRuleArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.SIPDispatchRuleInfo
+	}
+	deleteSIPDispatchRuleReturns struct {
+		result1 error
+	}
+	deleteSIPDispatchRuleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteSIPParticipantStub        func(context.Context, *livekit.SIPParticipantInfo) error
+	deleteSIPParticipantMutex       sync.RWMutex
+	deleteSIPParticipantArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.SIPParticipantInfo
+	}
+	deleteSIPParticipantReturns struct {
+		result1 error
+	}
+	deleteSIPParticipantReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteSIPTrunkStub        func(context.Context, *livekit.SIPTrunkInfo) error
+	deleteSIPTrunkMutex       sync.RWMutex
+	deleteSIPTrunkArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.SIPTrunkInfo
+	}
+	deleteSIPTrunkReturns struct {
+		result1 error
+	}
+	deleteSIPTrunkReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListSIPDispatchFailuresStub        func(context.Context) ([]*service.SIPDispatchFailure, error)
+	listSIPDispatchFailuresMutex       sync.RWMutex
+	listSIPDispatchFailuresArgsForCall []struct {
+		arg1 context.Context
+	}
+	listSIPDispatchFailuresReturns struct {
+		result1 []*service.SIPDispatchFailure
+		result2 error
+	}
+	listSIPDispatchFailuresReturnsOnCall map[int]struct {
+		result1 []*service.SIPDispatchFailure
+		result2 error
+	}
+	ListSIPDispatchRuleStub        func(context.Context) ([]*livekit.SIPDispatchRuleInfo, error)
+	listSIPDispatchRuleMutex       sync.RWMutex
+	listSIPDispatchRuleArgsForCall []struct {
+		arg1 context.Context
+	}
+	listSIPDispatchRuleReturns struct {
+		result1 []*livekit.SIPDispatchRuleInfo
+		result2 error
+	}
+	listSIPDispatchRuleReturnsOnCall map[int]struct {
+		result1 []*livekit.SIPDispatchRuleInfo
+		result2 error
+	}
+	ListSIPParticipantStub        func(context.Context) ([]*livekit.SIPParticipantInfo, error)
+	listSIPParticipantMutex       sync.RWMutex
+	listSIPParticipantArgsForCall []struct {
+		arg1 context.Context
+	}
+	listSIPParticipantReturns struct {
+		result1 []*livekit.SIPParticipantInfo
+		result2 error
+	}
+	listSIPParticipantReturnsOnCall map[int]struct {
+		result1 []*livekit.SIPParticipantInfo
+		result2 error
+	}
+	ListSIPParticipantEventsStub        func(context.Context, string) ([]*service.SIPParticipantEvent, error)
+	listSIPParticipantEventsMutex       sync.RWMutex
+	listSIPParticipantEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listSIPParticipantEventsReturns struct {
+		result1 []*service.SIPParticipantEvent
+		result2 error
+	}
+	listSIPParticipantEventsReturnsOnCall map[int]struct {
+		result1 []*service.SIPParticipantEvent
+		result2 error
+	}
+	ListSIPScheduledCallsStub        func(context.Context) ([]*service.SIPScheduledCall, error)
+	listSIPScheduledCallsMutex       sync.RWMutex
+	listSIPScheduledCallsArgsForCall []struct {
+		arg1 context.Context
+	}
+	listSIPScheduledCallsReturns struct {
+		result1 []*service.SIPScheduledCall
+		result2 error
+	}
+	listSIPScheduledCallsReturnsOnCall map[int]struct {
+		result1 []*service.SIPScheduledCall
+		result2 error
+	}
+	ListSIPTrunkStub        func(context.Context) ([]*livekit.SIPTrunkInfo, error)
+	listSIPTrunkMutex       sync.RWMutex
+	listSIPTrunkArgsForCall []struct {
+		arg1 context.Context
+	}
+	listSIPTrunkReturns struct {
+		result1 []*livekit.SIPTrunkInfo
+		result2 error
+	}
+	listSIPTrunkReturnsOnCall map[int]struct {
+		result1 []*livekit.SIPTrunkInfo
+		result2 error
+	}
+	LoadSIPDispatchRuleStub        func(context.Context, string) (*livekit.SIPDispatchRuleInfo, error)
+	loadSIPDispatchRuleMutex       sync.RWMutex
+	loadSIPDispatchRuleArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	loadSIPDispatchRuleReturns struct {
+		result1 *livekit.SIPDispatchRuleInfo
+		result2 error
+	}
+	loadSIPDispatchRuleReturnsOnCall map[int]struct {
+		result1 *livekit.SIPDispatchRuleInfo
+		result2 error
+	}
+	LoadSIPDispatchRuleActivityStub        func(context.Context) (map[string]*service.SIPDispatchRuleActivity, error)
+	loadSIPDispatchRuleActivityMutex       sync.RWMutex
+	loadSIPDispatchRuleActivityArgsForCall []struct {
+		arg1 context.Context
+	}
+	loadSIPDispatchRuleActivityReturns struct {
+		result1 map[string]*service.SIPDispatchRuleActivity
+		result2 error
+	}
+	loadSIPDispatchRuleActivityReturnsOnCall map[int]struct {
+		result1 map[string]*service.SIPDispatchRuleActivity
+		result2 error
+	}
+	LoadSIPParticipantStub        func(context.Context, string) (*livekit.SIPParticipantInfo, error)
+	loadSIPParticipantMutex       sync.RWMutex
+	loadSIPParticipantArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	loadSIPParticipantReturns struct {
+		result1 *livekit.SIPParticipantInfo
+		result2 error
+	}
+	loadSIPParticipantReturnsOnCall map[int]struct {
+		result1 *livekit.SIPParticipantInfo
+		result2 error
+	}
+	LoadSIPParticipantsStub        func(context.Context, []string) ([]*livekit.SIPParticipantInfo, error)
+	loadSIPParticipantsMutex       sync.RWMutex
+	loadSIPParticipantsArgsForCall []struct {
+		arg1 context.Context
+		arg2 []string
+	}
+	loadSIPParticipantsReturns struct {
+		result1 []*livekit.SIPParticipantInfo
+		result2 error
+	}
+	loadSIPParticipantsReturnsOnCall map[int]struct {
+		result1 []*livekit.SIPParticipantInfo
+		result2 error
+	}
+	LoadSIPTrunkStub        func(context.Context, string) (*livekit.SIPTrunkInfo, error)
+	loadSIPTrunkMutex       sync.RWMutex
+	loadSIPTrunkArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	loadSIPTrunkReturns struct {
+		result1 *livekit.SIPTrunkInfo
+		result2 error
+	}
+	loadSIPTrunkReturnsOnCall map[int]struct {
+		result1 *livekit.SIPTrunkInfo
+		result2 error
+	}
+	MarkSIPDispatchRulesSeenStub        func(context.Context, []string, time.Time) error
+	markSIPDispatchRulesSeenMutex       sync.RWMutex
+	markSIPDispatchRulesSeenArgsForCall []struct {
+		arg1 context.Context
+		arg2 []string
+		arg3 time.Time
+	}
+	markSIPDispatchRulesSeenReturns struct {
+		result1 error
+	}
+	markSIPDispatchRulesSeenReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RecordSIPDispatchRuleMatchStub        func(context.Context, string, time.Time) error
+	recordSIPDispatchRuleMatchMutex       sync.RWMutex
+	recordSIPDispatchRuleMatchArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}
+	recordSIPDispatchRuleMatchReturns struct {
+		result1 error
+	}
+	recordSIPDispatchRuleMatchReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RunSIPTxnStub        func(context.Context, func(tx service.SIPTxn) error) error
+	runSIPTxnMutex       sync.RWMutex
+	runSIPTxnArgsForCall []struct {
+		arg1 context.Context
+		arg2 func(tx service.SIPTxn) error
+	}
+	runSIPTxnReturns struct {
+		result1 error
+	}
+	runSIPTxnReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetSIPDispatchRuleRoomMissingStub        func(context.Context, string, time.Time) error
+	setSIPDispatchRuleRoomMissingMutex       sync.RWMutex
+	setSIPDispatchRuleRoomMissingArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}
+	setSIPDispatchRuleRoomMissingReturns struct {
+		result1 error
+	}
+	setSIPDispatchRuleRoomMissingReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreSIPDispatchRuleStub        func(context.Context, *livekit.SIPDispatchRuleInfo) error
+	storeSIPDispatchRuleMutex       sync.RWMutex
+	storeSIPDispatchRuleArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.SIPDispatchRuleInfo
+	}
+	storeSIPDispatchRuleReturns struct {
+		result1 error
+	}
+	storeSIPDispatchRuleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreSIPParticipantStub        func(context.Context, *livekit.SIPParticipantInfo) error
+	storeSIPParticipantMutex       sync.RWMutex
+	storeSIPParticipantArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.SIPParticipantInfo
+	}
+	storeSIPParticipantReturns struct {
+		result1 error
+	}
+	storeSIPParticipantReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreSIPScheduledCallStub        func(context.Context, *service.SIPScheduledCall) error
+	storeSIPScheduledCallMutex       sync.RWMutex
+	storeSIPScheduledCallArgsForCall []struct {
+		arg1 context.Context
+		arg2 *service.SIPScheduledCall
+	}
+	storeSIPScheduledCallReturns struct {
+		result1 error
+	}
+	storeSIPScheduledCallReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreSIPTrunkStub        func(context.Context, *livekit.SIPTrunkInfo) error
+	storeSIPTrunkMutex       sync.RWMutex
+	storeSIPTrunkArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.SIPTrunkInfo
+	}
+	storeSIPTrunkReturns struct {
+		result1 error
+	}
+	storeSIPTrunkReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSIPStore) AppendSIPDispatchFailure(arg1 context.Context, arg2 *service.SIPDispatchFailure, arg3 int) error {
+	fake.appendSIPDispatchFailureMutex.Lock()
+	ret, specificReturn := fake.appendSIPDispatchFailureReturnsOnCall[len(fake.appendSIPDispatchFailureArgsForCall)]
+	fake.appendSIPDispatchFailureArgsForCall = append(fake.appendSIPDispatchFailureArgsForCall, struct {
+		arg1 context.Context
+		arg2 *service.SIPDispatchFailure
+		arg3 int
+	}{arg1, arg2, arg3})
+	stub := fake.AppendSIPDispatchFailureStub
+	fakeReturns := fake.appendSIPDispatchFailureReturns
+	fake.recordInvocation("AppendSIPDispatchFailure", []interface{}{arg1, arg2, arg3})
+	fake.appendSIPDispatchFailureMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) AppendSIPDispatchFailureCallCount() int {
+	fake.appendSIPDispatchFailureMutex.RLock()
+	defer fake.appendSIPDispatchFailureMutex.RUnlock()
+	return len(fake.appendSIPDispatchFailureArgsForCall)
+}
+
+func (fake *FakeSIPStore) AppendSIPDispatchFailureCalls(stub func(context.Context, *service.SIPDispatchFailure, int) error) {
+	fake.appendSIPDispatchFailureMutex.Lock()
+	defer fake.appendSIPDispatchFailureMutex.Unlock()
+	fake.AppendSIPDispatchFailureStub = stub
+}
+
+func (fake *FakeSIPStore) AppendSIPDispatchFailureArgsForCall(i int) (context.Context, *service.SIPDispatchFailure, int) {
+	fake.appendSIPDispatchFailureMutex.RLock()
+	defer fake.appendSIPDispatchFailureMutex.RUnlock()
+	argsForCall := fake.appendSIPDispatchFailureArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeSIPStore) AppendSIPDispatchFailureReturns(result1 error) {
+	fake.appendSIPDispatchFailureMutex.Lock()
+	defer fake.appendSIPDispatchFailureMutex.Unlock()
+	fake.AppendSIPDispatchFailureStub = nil
+	fake.appendSIPDispatchFailureReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) AppendSIPDispatchFailureReturnsOnCall(i int, result1 error) {
+	fake.appendSIPDispatchFailureMutex.Lock()
+	defer fake.appendSIPDispatchFailureMutex.Unlock()
+	fake.AppendSIPDispatchFailureStub = nil
+	if fake.appendSIPDispatchFailureReturnsOnCall == nil {
+		fake.appendSIPDispatchFailureReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.appendSIPDispatchFailureReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) AppendSIPParticipantEvent(arg1 context.Context, arg2 string, arg3 *service.SIPParticipantEvent, arg4 int, arg5 time.Duration) error {
+	fake.appendSIPParticipantEventMutex.Lock()
+	ret, specificReturn := fake.appendSIPParticipantEventReturnsOnCall[len(fake.appendSIPParticipantEventArgsForCall)]
+	fake.appendSIPParticipantEventArgsForCall = append(fake.appendSIPParticipantEventArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 *service.SIPParticipantEvent
+		arg4 int
+		arg5 time.Duration
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.AppendSIPParticipantEventStub
+	fakeReturns := fake.appendSIPParticipantEventReturns
+	fake.recordInvocation("AppendSIPParticipantEvent", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.appendSIPParticipantEventMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) AppendSIPParticipantEventCallCount() int {
+	fake.appendSIPParticipantEventMutex.RLock()
+	defer fake.appendSIPParticipantEventMutex.RUnlock()
+	return len(fake.appendSIPParticipantEventArgsForCall)
+}
+
+func (fake *FakeSIPStore) AppendSIPParticipantEventCalls(stub func(context.Context, string, *service.SIPParticipantEvent, int, time.Duration) error) {
+	fake.appendSIPParticipantEventMutex.Lock()
+	defer fake.appendSIPParticipantEventMutex.Unlock()
+	fake.AppendSIPParticipantEventStub = stub
+}
+
+func (fake *FakeSIPStore) AppendSIPParticipantEventArgsForCall(i int) (context.Context, string, *service.SIPParticipantEvent, int, time.Duration) {
+	fake.appendSIPParticipantEventMutex.RLock()
+	defer fake.appendSIPParticipantEventMutex.RUnlock()
+	argsForCall := fake.appendSIPParticipantEventArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeSIPStore) AppendSIPParticipantEventReturns(result1 error) {
+	fake.appendSIPParticipantEventMutex.Lock()
+	defer fake.appendSIPParticipantEventMutex.Unlock()
+	fake.AppendSIPParticipantEventStub = nil
+	fake.appendSIPParticipantEventReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) AppendSIPParticipantEventReturnsOnCall(i int, result1 error) {
+	fake.appendSIPParticipantEventMutex.Lock()
+	defer fake.appendSIPParticipantEventMutex.Unlock()
+	fake.AppendSIPParticipantEventStub = nil
+	if fake.appendSIPParticipantEventReturnsOnCall == nil {
+		fake.appendSIPParticipantEventReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.appendSIPParticipantEventReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) CancelSIPScheduledCall(arg1 context.Context, arg2 string) error {
+	fake.cancelSIPScheduledCallMutex.Lock()
+	ret, specificReturn := fake.cancelSIPScheduledCallReturnsOnCall[len(fake.cancelSIPScheduledCallArgsForCall)]
+	fake.cancelSIPScheduledCallArgsForCall = append(fake.cancelSIPScheduledCallArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.CancelSIPScheduledCallStub
+	fakeReturns := fake.cancelSIPScheduledCallReturns
+	fake.recordInvocation("CancelSIPScheduledCall", []interface{}{arg1, arg2})
+	fake.cancelSIPScheduledCallMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) CancelSIPScheduledCallCallCount() int {
+	fake.cancelSIPScheduledCallMutex.RLock()
+	defer fake.cancelSIPScheduledCallMutex.RUnlock()
+	return len(fake.cancelSIPScheduledCallArgsForCall)
+}
+
+func (fake *FakeSIPStore) CancelSIPScheduledCallCalls(stub func(context.Context, string) error) {
+	fake.cancelSIPScheduledCallMutex.Lock()
+	defer fake.cancelSIPScheduledCallMutex.Unlock()
+	fake.CancelSIPScheduledCallStub = stub
+}
+
+func (fake *FakeSIPStore) CancelSIPScheduledCallArgsForCall(i int) (context.Context, string) {
+	fake.cancelSIPScheduledCallMutex.RLock()
+	defer fake.cancelSIPScheduledCallMutex.RUnlock()
+	argsForCall := fake.cancelSIPScheduledCallArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) CancelSIPScheduledCallReturns(result1 error) {
+	fake.cancelSIPScheduledCallMutex.Lock()
+	defer fake.cancelSIPScheduledCallMutex.Unlock()
+	fake.CancelSIPScheduledCallStub = nil
+	fake.cancelSIPScheduledCallReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) CancelSIPScheduledCallReturnsOnCall(i int, result1 error) {
+	fake.cancelSIPScheduledCallMutex.Lock()
+	defer fake.cancelSIPScheduledCallMutex.Unlock()
+	fake.CancelSIPScheduledCallStub = nil
+	if fake.cancelSIPScheduledCallReturnsOnCall == nil {
+		fake.cancelSIPScheduledCallReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cancelSIPScheduledCallReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) ClaimSIPScheduledCalls(arg1 context.Context, arg2 time.Time, arg3 time.Time, arg4 int) ([]*service.SIPScheduledCall, error) {
+	fake.claimSIPScheduledCallsMutex.Lock()
+	ret, specificReturn := fake.claimSIPScheduledCallsReturnsOnCall[len(fake.claimSIPScheduledCallsArgsForCall)]
+	fake.claimSIPScheduledCallsArgsForCall = append(fake.claimSIPScheduledCallsArgsForCall, struct {
+		arg1 context.Context
+		arg2 time.Time
+		arg3 time.Time
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ClaimSIPScheduledCallsStub
+	fakeReturns := fake.claimSIPScheduledCallsReturns
+	fake.recordInvocation("ClaimSIPScheduledCalls", []interface{}{arg1, arg2, arg3, arg4})
+	fake.claimSIPScheduledCallsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) ClaimSIPScheduledCallsCallCount() int {
+	fake.claimSIPScheduledCallsMutex.RLock()
+	defer fake.claimSIPScheduledCallsMutex.RUnlock()
+	return len(fake.claimSIPScheduledCallsArgsForCall)
+}
+
+func (fake *FakeSIPStore) ClaimSIPScheduledCallsCalls(stub func(context.Context, time.Time, time.Time, int) ([]*service.SIPScheduledCall, error)) {
+	fake.claimSIPScheduledCallsMutex.Lock()
+	defer fake.claimSIPScheduledCallsMutex.Unlock()
+	fake.ClaimSIPScheduledCallsStub = stub
+}
+
+func (fake *FakeSIPStore) ClaimSIPScheduledCallsArgsForCall(i int) (context.Context, time.Time, time.Time, int) {
+	fake.claimSIPScheduledCallsMutex.RLock()
+	defer fake.claimSIPScheduledCallsMutex.RUnlock()
+	argsForCall := fake.claimSIPScheduledCallsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeSIPStore) ClaimSIPScheduledCallsReturns(result1 []*service.SIPScheduledCall, result2 error) {
+	fake.claimSIPScheduledCallsMutex.Lock()
+	defer fake.claimSIPScheduledCallsMutex.Unlock()
+	fake.ClaimSIPScheduledCallsStub = nil
+	fake.claimSIPScheduledCallsReturns = struct {
+		result1 []*service.SIPScheduledCall
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ClaimSIPScheduledCallsReturnsOnCall(i int, result1 []*service.SIPScheduledCall, result2 error) {
+	fake.claimSIPScheduledCallsMutex.Lock()
+	defer fake.claimSIPScheduledCallsMutex.Unlock()
+	fake.ClaimSIPScheduledCallsStub = nil
+	if fake.claimSIPScheduledCallsReturnsOnCall == nil {
+		fake.claimSIPScheduledCallsReturnsOnCall = make(map[int]struct {
+			result1 []*service.SIPScheduledCall
+			result2 error
+		})
+	}
+	fake.claimSIPScheduledCallsReturnsOnCall[i] = struct {
+		result1 []*service.SIPScheduledCall
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) CompleteSIPScheduledCall(arg1 context.Context, arg2 string) error {
+	fake.completeSIPScheduledCallMutex.Lock()
+	ret, specificReturn := fake.completeSIPScheduledCallReturnsOnCall[len(fake.completeSIPScheduledCallArgsForCall)]
+	fake.completeSIPScheduledCallArgsForCall = append(fake.completeSIPScheduledCallArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.CompleteSIPScheduledCallStub
+	fakeReturns := fake.completeSIPScheduledCallReturns
+	fake.recordInvocation("CompleteSIPScheduledCall", []interface{}{arg1, arg2})
+	fake.completeSIPScheduledCallMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) CompleteSIPScheduledCallCallCount() int {
+	fake.completeSIPScheduledCallMutex.RLock()
+	defer fake.completeSIPScheduledCallMutex.RUnlock()
+	return len(fake.completeSIPScheduledCallArgsForCall)
+}
+
+func (fake *FakeSIPStore) CompleteSIPScheduledCallCalls(stub func(context.Context, string) error) {
+	fake.completeSIPScheduledCallMutex.Lock()
+	defer fake.completeSIPScheduledCallMutex.Unlock()
+	fake.CompleteSIPScheduledCallStub = stub
+}
+
+func (fake *FakeSIPStore) CompleteSIPScheduledCallArgsForCall(i int) (context.Context, string) {
+	fake.completeSIPScheduledCallMutex.RLock()
+	defer fake.completeSIPScheduledCallMutex.RUnlock()
+	argsForCall := fake.completeSIPScheduledCallArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) CompleteSIPScheduledCallReturns(result1 error) {
+	fake.completeSIPScheduledCallMutex.Lock()
+	defer fake.completeSIPScheduledCallMutex.Unlock()
+	fake.CompleteSIPScheduledCallStub = nil
+	fake.completeSIPScheduledCallReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) CompleteSIPScheduledCallReturnsOnCall(i int, result1 error) {
+	fake.completeSIPScheduledCallMutex.Lock()
+	defer fake.completeSIPScheduledCallMutex.Unlock()
+	fake.CompleteSIPScheduledCallStub = nil
+	if fake.completeSIPScheduledCallReturnsOnCall == nil {
+		fake.completeSIPScheduledCallReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.completeSIPScheduledCallReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) DeleteSIPDispatchRule(arg1 context.Context, arg2 *livekit.SIPDispatchRuleInfo) error {
+	fake.deleteSIPDispatchRuleMutex.Lock()
+	ret, specificReturn := fake.deleteSIPDispatchRuleReturnsOnCall[len(fake.deleteSIPDispatchRuleArgsForCall)]
+	fake.deleteSIPDispatchRuleArgsForCall = append(fake.deleteSIPDispatchRuleArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.SIPDispatchRuleInfo
+	}{arg1, arg2})
+	stub := fake.DeleteSIPDispatchRuleStub
+	fakeReturns := fake.deleteSIPDispatchRuleReturns
+	fake.recordInvocation("DeleteSIPDispatchRule", []interface{}{arg1, arg2})
+	fake.deleteSIPDispatchRuleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) DeleteSIPDispatchRuleCallCount() int {
+	fake.deleteSIPDispatchRuleMutex.RLock()
+	defer fake.deleteSIPDispatchRuleMutex.RUnlock()
+	return len(fake.deleteSIPDispatchRuleArgsForCall)
+}
+
+func (fake *FakeSIPStore) DeleteSIPDispatchRuleCalls(stub func(context.Context, *livekit.SIPDispatchRuleInfo) error) {
+	fake.deleteSIPDispatchRuleMutex.Lock()
+	defer fake.deleteSIPDispatchRuleMutex.Unlock()
+	fake.DeleteSIPDispatchRuleStub = stub
+}
+
+func (fake *FakeSIPStore) DeleteSIPDispatchRuleArgsForCall(i int) (context.Context, *livekit.SIPDispatchRuleInfo) {
+	fake.deleteSIPDispatchRuleMutex.RLock()
+	defer fake.deleteSIPDispatchRuleMutex.RUnlock()
+	argsForCall := fake.deleteSIPDispatchRuleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) DeleteSIPDispatchRuleReturns(result1 error) {
+	fake.deleteSIPDispatchRuleMutex.Lock()
+	defer fake.deleteSIPDispatchRuleMutex.Unlock()
+	fake.DeleteSIPDispatchRuleStub = nil
+	fake.deleteSIPDispatchRuleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) DeleteSIPDispatchRuleReturnsOnCall(i int, result1 error) {
+	fake.deleteSIPDispatchRuleMutex.Lock()
+	defer fake.deleteSIPDispatchRuleMutex.Unlock()
+	fake.DeleteSIPDispatchRuleStub = nil
+	if fake.deleteSIPDispatchRuleReturnsOnCall == nil {
+		fake.deleteSIPDispatchRuleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteSIPDispatchRuleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) DeleteSIPParticipant(arg1 context.Context, arg2 *livekit.SIPParticipantInfo) error {
+	fake.deleteSIPParticipantMutex.Lock()
+	ret, specificReturn := fake.deleteSIPParticipantReturnsOnCall[len(fake.deleteSIPParticipantArgsForCall)]
+	fake.deleteSIPParticipantArgsForCall = append(fake.deleteSIPParticipantArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.SIPParticipantInfo
+	}{arg1, arg2})
+	stub := fake.DeleteSIPParticipantStub
+	fakeReturns := fake.deleteSIPParticipantReturns
+	fake.recordInvocation("DeleteSIPParticipant", []interface{}{arg1, arg2})
+	fake.deleteSIPParticipantMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) DeleteSIPParticipantCallCount() int {
+	fake.deleteSIPParticipantMutex.RLock()
+	defer fake.deleteSIPParticipantMutex.RUnlock()
+	return len(fake.deleteSIPParticipantArgsForCall)
+}
+
+func (fake *FakeSIPStore) DeleteSIPParticipantCalls(stub func(context.Context, *livekit.SIPParticipantInfo) error) {
+	fake.deleteSIPParticipantMutex.Lock()
+	defer fake.deleteSIPParticipantMutex.Unlock()
+	fake.DeleteSIPParticipantStub = stub
+}
+
+func (fake *FakeSIPStore) DeleteSIPParticipantArgsForCall(i int) (context.Context, *livekit.SIPParticipantInfo) {
+	fake.deleteSIPParticipantMutex.RLock()
+	defer fake.deleteSIPParticipantMutex.RUnlock()
+	argsForCall := fake.deleteSIPParticipantArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) DeleteSIPParticipantReturns(result1 error) {
+	fake.deleteSIPParticipantMutex.Lock()
+	defer fake.deleteSIPParticipantMutex.Unlock()
+	fake.DeleteSIPParticipantStub = nil
+	fake.deleteSIPParticipantReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) DeleteSIPParticipantReturnsOnCall(i int, result1 error) {
+	fake.deleteSIPParticipantMutex.Lock()
+	defer fake.deleteSIPParticipantMutex.Unlock()
+	fake.DeleteSIPParticipantStub = nil
+	if fake.deleteSIPParticipantReturnsOnCall == nil {
+		fake.deleteSIPParticipantReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteSIPParticipantReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) DeleteSIPTrunk(arg1 context.Context, arg2 *livekit.SIPTrunkInfo) error {
+	fake.deleteSIPTrunkMutex.Lock()
+	ret, specificReturn := fake.deleteSIPTrunkReturnsOnCall[len(fake.deleteSIPTrunkArgsForCall)]
+	fake.deleteSIPTrunkArgsForCall = append(fake.deleteSIPTrunkArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.SIPTrunkInfo
+	}{arg1, arg2})
+	stub := fake.DeleteSIPTrunkStub
+	fakeReturns := fake.deleteSIPTrunkReturns
+	fake.recordInvocation("DeleteSIPTrunk", []interface{}{arg1, arg2})
+	fake.deleteSIPTrunkMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) DeleteSIPTrunkCallCount() int {
+	fake.deleteSIPTrunkMutex.RLock()
+	defer fake.deleteSIPTrunkMutex.RUnlock()
+	return len(fake.deleteSIPTrunkArgsForCall)
+}
+
+func (fake *FakeSIPStore) DeleteSIPTrunkCalls(stub func(context.Context, *livekit.SIPTrunkInfo) error) {
+	fake.deleteSIPTrunkMutex.Lock()
+	defer fake.deleteSIPTrunkMutex.Unlock()
+	fake.DeleteSIPTrunkStub = stub
+}
+
+func (fake *FakeSIPStore) DeleteSIPTrunkArgsForCall(i int) (context.Context, *livekit.SIPTrunkInfo) {
+	fake.deleteSIPTrunkMutex.RLock()
+	defer fake.deleteSIPTrunkMutex.RUnlock()
+	argsForCall := fake.deleteSIPTrunkArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) DeleteSIPTrunkReturns(result1 error) {
+	fake.deleteSIPTrunkMutex.Lock()
+	defer fake.deleteSIPTrunkMutex.Unlock()
+	fake.DeleteSIPTrunkStub = nil
+	fake.deleteSIPTrunkReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) DeleteSIPTrunkReturnsOnCall(i int, result1 error) {
+	fake.deleteSIPTrunkMutex.Lock()
+	defer fake.deleteSIPTrunkMutex.Unlock()
+	fake.DeleteSIPTrunkStub = nil
+	if fake.deleteSIPTrunkReturnsOnCall == nil {
+		fake.deleteSIPTrunkReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteSIPTrunkReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchFailures(arg1 context.Context) ([]*service.SIPDispatchFailure, error) {
+	fake.listSIPDispatchFailuresMutex.Lock()
+	ret, specificReturn := fake.listSIPDispatchFailuresReturnsOnCall[len(fake.listSIPDispatchFailuresArgsForCall)]
+	fake.listSIPDispatchFailuresArgsForCall = append(fake.listSIPDispatchFailuresArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ListSIPDispatchFailuresStub
+	fakeReturns := fake.listSIPDispatchFailuresReturns
+	fake.recordInvocation("ListSIPDispatchFailures", []interface{}{arg1})
+	fake.listSIPDispatchFailuresMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchFailuresCallCount() int {
+	fake.listSIPDispatchFailuresMutex.RLock()
+	defer fake.listSIPDispatchFailuresMutex.RUnlock()
+	return len(fake.listSIPDispatchFailuresArgsForCall)
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchFailuresCalls(stub func(context.Context) ([]*service.SIPDispatchFailure, error)) {
+	fake.listSIPDispatchFailuresMutex.Lock()
+	defer fake.listSIPDispatchFailuresMutex.Unlock()
+	fake.ListSIPDispatchFailuresStub = stub
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchFailuresArgsForCall(i int) context.Context {
+	fake.listSIPDispatchFailuresMutex.RLock()
+	defer fake.listSIPDispatchFailuresMutex.RUnlock()
+	argsForCall := fake.listSIPDispatchFailuresArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchFailuresReturns(result1 []*service.SIPDispatchFailure, result2 error) {
+	fake.listSIPDispatchFailuresMutex.Lock()
+	defer fake.listSIPDispatchFailuresMutex.Unlock()
+	fake.ListSIPDispatchFailuresStub = nil
+	fake.listSIPDispatchFailuresReturns = struct {
+		result1 []*service.SIPDispatchFailure
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchFailuresReturnsOnCall(i int, result1 []*service.SIPDispatchFailure, result2 error) {
+	fake.listSIPDispatchFailuresMutex.Lock()
+	defer fake.listSIPDispatchFailuresMutex.Unlock()
+	fake.ListSIPDispatchFailuresStub = nil
+	if fake.listSIPDispatchFailuresReturnsOnCall == nil {
+		fake.listSIPDispatchFailuresReturnsOnCall = make(map[int]struct {
+			result1 []*service.SIPDispatchFailure
+			result2 error
+		})
+	}
+	fake.listSIPDispatchFailuresReturnsOnCall[i] = struct {
+		result1 []*service.SIPDispatchFailure
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchRule(arg1 context.Context) ([]*livekit.SIPDispatchRuleInfo, error) {
+	fake.listSIPDispatchRuleMutex.Lock()
+	ret, specificReturn := fake.listSIPDispatchRuleReturnsOnCall[len(fake.listSIPDispatchRuleArgsForCall)]
+	fake.listSIPDispatchRuleArgsForCall = append(fake.listSIPDispatchRuleArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ListSIPDispatchRuleStub
+	fakeReturns := fake.listSIPDispatchRuleReturns
+	fake.recordInvocation("ListSIPDispatchRule", []interface{}{arg1})
+	fake.listSIPDispatchRuleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchRuleCallCount() int {
+	fake.listSIPDispatchRuleMutex.RLock()
+	defer fake.listSIPDispatchRuleMutex.RUnlock()
+	return len(fake.listSIPDispatchRuleArgsForCall)
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchRuleCalls(stub func(context.Context) ([]*livekit.SIPDispatchRuleInfo, error)) {
+	fake.listSIPDispatchRuleMutex.Lock()
+	defer fake.listSIPDispatchRuleMutex.Unlock()
+	fake.ListSIPDispatchRuleStub = stub
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchRuleArgsForCall(i int) context.Context {
+	fake.listSIPDispatchRuleMutex.RLock()
+	defer fake.listSIPDispatchRuleMutex.RUnlock()
+	argsForCall := fake.listSIPDispatchRuleArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchRuleReturns(result1 []*livekit.SIPDispatchRuleInfo, result2 error) {
+	fake.listSIPDispatchRuleMutex.Lock()
+	defer fake.listSIPDispatchRuleMutex.Unlock()
+	fake.ListSIPDispatchRuleStub = nil
+	fake.listSIPDispatchRuleReturns = struct {
+		result1 []*livekit.SIPDispatchRuleInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPDispatchRuleReturnsOnCall(i int, result1 []*livekit.SIPDispatchRuleInfo, result2 error) {
+	fake.listSIPDispatchRuleMutex.Lock()
+	defer fake.listSIPDispatchRuleMutex.Unlock()
+	fake.ListSIPDispatchRuleStub = nil
+	if fake.listSIPDispatchRuleReturnsOnCall == nil {
+		fake.listSIPDispatchRuleReturnsOnCall = make(map[int]struct {
+			result1 []*livekit.SIPDispatchRuleInfo
+			result2 error
+		})
+	}
+	fake.listSIPDispatchRuleReturnsOnCall[i] = struct {
+		result1 []*livekit.SIPDispatchRuleInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPParticipant(arg1 context.Context) ([]*livekit.SIPParticipantInfo, error) {
+	fake.listSIPParticipantMutex.Lock()
+	ret, specificReturn := fake.listSIPParticipantReturnsOnCall[len(fake.listSIPParticipantArgsForCall)]
+	fake.listSIPParticipantArgsForCall = append(fake.listSIPParticipantArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ListSIPParticipantStub
+	fakeReturns := fake.listSIPParticipantReturns
+	fake.recordInvocation("ListSIPParticipant", []interface{}{arg1})
+	fake.listSIPParticipantMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantCallCount() int {
+	fake.listSIPParticipantMutex.RLock()
+	defer fake.listSIPParticipantMutex.RUnlock()
+	return len(fake.listSIPParticipantArgsForCall)
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantCalls(stub func(context.Context) ([]*livekit.SIPParticipantInfo, error)) {
+	fake.listSIPParticipantMutex.Lock()
+	defer fake.listSIPParticipantMutex.Unlock()
+	fake.ListSIPParticipantStub = stub
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantArgsForCall(i int) context.Context {
+	fake.listSIPParticipantMutex.RLock()
+	defer fake.listSIPParticipantMutex.RUnlock()
+	argsForCall := fake.listSIPParticipantArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantReturns(result1 []*livekit.SIPParticipantInfo, result2 error) {
+	fake.listSIPParticipantMutex.Lock()
+	defer fake.listSIPParticipantMutex.Unlock()
+	fake.ListSIPParticipantStub = nil
+	fake.listSIPParticipantReturns = struct {
+		result1 []*livekit.SIPParticipantInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantReturnsOnCall(i int, result1 []*livekit.SIPParticipantInfo, result2 error) {
+	fake.listSIPParticipantMutex.Lock()
+	defer fake.listSIPParticipantMutex.Unlock()
+	fake.ListSIPParticipantStub = nil
+	if fake.listSIPParticipantReturnsOnCall == nil {
+		fake.listSIPParticipantReturnsOnCall = make(map[int]struct {
+			result1 []*livekit.SIPParticipantInfo
+			result2 error
+		})
+	}
+	fake.listSIPParticipantReturnsOnCall[i] = struct {
+		result1 []*livekit.SIPParticipantInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantEvents(arg1 context.Context, arg2 string) ([]*service.SIPParticipantEvent, error) {
+	fake.listSIPParticipantEventsMutex.Lock()
+	ret, specificReturn := fake.listSIPParticipantEventsReturnsOnCall[len(fake.listSIPParticipantEventsArgsForCall)]
+	fake.listSIPParticipantEventsArgsForCall = append(fake.listSIPParticipantEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListSIPParticipantEventsStub
+	fakeReturns := fake.listSIPParticipantEventsReturns
+	fake.recordInvocation("ListSIPParticipantEvents", []interface{}{arg1, arg2})
+	fake.listSIPParticipantEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantEventsCallCount() int {
+	fake.listSIPParticipantEventsMutex.RLock()
+	defer fake.listSIPParticipantEventsMutex.RUnlock()
+	return len(fake.listSIPParticipantEventsArgsForCall)
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantEventsCalls(stub func(context.Context, string) ([]*service.SIPParticipantEvent, error)) {
+	fake.listSIPParticipantEventsMutex.Lock()
+	defer fake.listSIPParticipantEventsMutex.Unlock()
+	fake.ListSIPParticipantEventsStub = stub
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantEventsArgsForCall(i int) (context.Context, string) {
+	fake.listSIPParticipantEventsMutex.RLock()
+	defer fake.listSIPParticipantEventsMutex.RUnlock()
+	argsForCall := fake.listSIPParticipantEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantEventsReturns(result1 []*service.SIPParticipantEvent, result2 error) {
+	fake.listSIPParticipantEventsMutex.Lock()
+	defer fake.listSIPParticipantEventsMutex.Unlock()
+	fake.ListSIPParticipantEventsStub = nil
+	fake.listSIPParticipantEventsReturns = struct {
+		result1 []*service.SIPParticipantEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPParticipantEventsReturnsOnCall(i int, result1 []*service.SIPParticipantEvent, result2 error) {
+	fake.listSIPParticipantEventsMutex.Lock()
+	defer fake.listSIPParticipantEventsMutex.Unlock()
+	fake.ListSIPParticipantEventsStub = nil
+	if fake.listSIPParticipantEventsReturnsOnCall == nil {
+		fake.listSIPParticipantEventsReturnsOnCall = make(map[int]struct {
+			result1 []*service.SIPParticipantEvent
+			result2 error
+		})
+	}
+	fake.listSIPParticipantEventsReturnsOnCall[i] = struct {
+		result1 []*service.SIPParticipantEvent
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPScheduledCalls(arg1 context.Context) ([]*service.SIPScheduledCall, error) {
+	fake.listSIPScheduledCallsMutex.Lock()
+	ret, specificReturn := fake.listSIPScheduledCallsReturnsOnCall[len(fake.listSIPScheduledCallsArgsForCall)]
+	fake.listSIPScheduledCallsArgsForCall = append(fake.listSIPScheduledCallsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ListSIPScheduledCallsStub
+	fakeReturns := fake.listSIPScheduledCallsReturns
+	fake.recordInvocation("ListSIPScheduledCalls", []interface{}{arg1})
+	fake.listSIPScheduledCallsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) ListSIPScheduledCallsCallCount() int {
+	fake.listSIPScheduledCallsMutex.RLock()
+	defer fake.listSIPScheduledCallsMutex.RUnlock()
+	return len(fake.listSIPScheduledCallsArgsForCall)
+}
+
+func (fake *FakeSIPStore) ListSIPScheduledCallsCalls(stub func(context.Context) ([]*service.SIPScheduledCall, error)) {
+	fake.listSIPScheduledCallsMutex.Lock()
+	defer fake.listSIPScheduledCallsMutex.Unlock()
+	fake.ListSIPScheduledCallsStub = stub
+}
+
+func (fake *FakeSIPStore) ListSIPScheduledCallsArgsForCall(i int) context.Context {
+	fake.listSIPScheduledCallsMutex.RLock()
+	defer fake.listSIPScheduledCallsMutex.RUnlock()
+	argsForCall := fake.listSIPScheduledCallsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSIPStore) ListSIPScheduledCallsReturns(result1 []*service.SIPScheduledCall, result2 error) {
+	fake.listSIPScheduledCallsMutex.Lock()
+	defer fake.listSIPScheduledCallsMutex.Unlock()
+	fake.ListSIPScheduledCallsStub = nil
+	fake.listSIPScheduledCallsReturns = struct {
+		result1 []*service.SIPScheduledCall
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPScheduledCallsReturnsOnCall(i int, result1 []*service.SIPScheduledCall, result2 error) {
+	fake.listSIPScheduledCallsMutex.Lock()
+	defer fake.listSIPScheduledCallsMutex.Unlock()
+	fake.ListSIPScheduledCallsStub = nil
+	if fake.listSIPScheduledCallsReturnsOnCall == nil {
+		fake.listSIPScheduledCallsReturnsOnCall = make(map[int]struct {
+			result1 []*service.SIPScheduledCall
+			result2 error
+		})
+	}
+	fake.listSIPScheduledCallsReturnsOnCall[i] = struct {
+		result1 []*service.SIPScheduledCall
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPTrunk(arg1 context.Context) ([]*livekit.SIPTrunkInfo, error) {
+	fake.listSIPTrunkMutex.Lock()
+	ret, specificReturn := fake.listSIPTrunkReturnsOnCall[len(fake.listSIPTrunkArgsForCall)]
+	fake.listSIPTrunkArgsForCall = append(fake.listSIPTrunkArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ListSIPTrunkStub
+	fakeReturns := fake.listSIPTrunkReturns
+	fake.recordInvocation("ListSIPTrunk", []interface{}{arg1})
+	fake.listSIPTrunkMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) ListSIPTrunkCallCount() int {
+	fake.listSIPTrunkMutex.RLock()
+	defer fake.listSIPTrunkMutex.RUnlock()
+	return len(fake.listSIPTrunkArgsForCall)
+}
+
+func (fake *FakeSIPStore) ListSIPTrunkCalls(stub func(context.Context) ([]*livekit.SIPTrunkInfo, error)) {
+	fake.listSIPTrunkMutex.Lock()
+	defer fake.listSIPTrunkMutex.Unlock()
+	fake.ListSIPTrunkStub = stub
+}
+
+func (fake *FakeSIPStore) ListSIPTrunkArgsForCall(i int) context.Context {
+	fake.listSIPTrunkMutex.RLock()
+	defer fake.listSIPTrunkMutex.RUnlock()
+	argsForCall := fake.listSIPTrunkArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSIPStore) ListSIPTrunkReturns(result1 []*livekit.SIPTrunkInfo, result2 error) {
+	fake.listSIPTrunkMutex.Lock()
+	defer fake.listSIPTrunkMutex.Unlock()
+	fake.ListSIPTrunkStub = nil
+	fake.listSIPTrunkReturns = struct {
+		result1 []*livekit.SIPTrunkInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) ListSIPTrunkReturnsOnCall(i int, result1 []*livekit.SIPTrunkInfo, result2 error) {
+	fake.listSIPTrunkMutex.Lock()
+	defer fake.listSIPTrunkMutex.Unlock()
+	fake.ListSIPTrunkStub = nil
+	if fake.listSIPTrunkReturnsOnCall == nil {
+		fake.listSIPTrunkReturnsOnCall = make(map[int]struct {
+			result1 []*livekit.SIPTrunkInfo
+			result2 error
+		})
+	}
+	fake.listSIPTrunkReturnsOnCall[i] = struct {
+		result1 []*livekit.SIPTrunkInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRule(arg1 context.Context, arg2 string) (*livekit.SIPDispatchRuleInfo, error) {
+	fake.loadSIPDispatchRuleMutex.Lock()
+	ret, specificReturn := fake.loadSIPDispatchRuleReturnsOnCall[len(fake.loadSIPDispatchRuleArgsForCall)]
+	fake.loadSIPDispatchRuleArgsForCall = append(fake.loadSIPDispatchRuleArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.LoadSIPDispatchRuleStub
+	fakeReturns := fake.loadSIPDispatchRuleReturns
+	fake.recordInvocation("LoadSIPDispatchRule", []interface{}{arg1, arg2})
+	fake.loadSIPDispatchRuleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleCallCount() int {
+	fake.loadSIPDispatchRuleMutex.RLock()
+	defer fake.loadSIPDispatchRuleMutex.RUnlock()
+	return len(fake.loadSIPDispatchRuleArgsForCall)
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleCalls(stub func(context.Context, string) (*livekit.SIPDispatchRuleInfo, error)) {
+	fake.loadSIPDispatchRuleMutex.Lock()
+	defer fake.loadSIPDispatchRuleMutex.Unlock()
+	fake.LoadSIPDispatchRuleStub = stub
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleArgsForCall(i int) (context.Context, string) {
+	fake.loadSIPDispatchRuleMutex.RLock()
+	defer fake.loadSIPDispatchRuleMutex.RUnlock()
+	argsForCall := fake.loadSIPDispatchRuleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleReturns(result1 *livekit.SIPDispatchRuleInfo, result2 error) {
+	fake.loadSIPDispatchRuleMutex.Lock()
+	defer fake.loadSIPDispatchRuleMutex.Unlock()
+	fake.LoadSIPDispatchRuleStub = nil
+	fake.loadSIPDispatchRuleReturns = struct {
+		result1 *livekit.SIPDispatchRuleInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleReturnsOnCall(i int, result1 *livekit.SIPDispatchRuleInfo, result2 error) {
+	fake.loadSIPDispatchRuleMutex.Lock()
+	defer fake.loadSIPDispatchRuleMutex.Unlock()
+	fake.LoadSIPDispatchRuleStub = nil
+	if fake.loadSIPDispatchRuleReturnsOnCall == nil {
+		fake.loadSIPDispatchRuleReturnsOnCall = make(map[int]struct {
+			result1 *livekit.SIPDispatchRuleInfo
+			result2 error
+		})
+	}
+	fake.loadSIPDispatchRuleReturnsOnCall[i] = struct {
+		result1 *livekit.SIPDispatchRuleInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleActivity(arg1 context.Context) (map[string]*service.SIPDispatchRuleActivity, error) {
+	fake.loadSIPDispatchRuleActivityMutex.Lock()
+	ret, specificReturn := fake.loadSIPDispatchRuleActivityReturnsOnCall[len(fake.loadSIPDispatchRuleActivityArgsForCall)]
+	fake.loadSIPDispatchRuleActivityArgsForCall = append(fake.loadSIPDispatchRuleActivityArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.LoadSIPDispatchRuleActivityStub
+	fakeReturns := fake.loadSIPDispatchRuleActivityReturns
+	fake.recordInvocation("LoadSIPDispatchRuleActivity", []interface{}{arg1})
+	fake.loadSIPDispatchRuleActivityMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleActivityCallCount() int {
+	fake.loadSIPDispatchRuleActivityMutex.RLock()
+	defer fake.loadSIPDispatchRuleActivityMutex.RUnlock()
+	return len(fake.loadSIPDispatchRuleActivityArgsForCall)
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleActivityCalls(stub func(context.Context) (map[string]*service.SIPDispatchRuleActivity, error)) {
+	fake.loadSIPDispatchRuleActivityMutex.Lock()
+	defer fake.loadSIPDispatchRuleActivityMutex.Unlock()
+	fake.LoadSIPDispatchRuleActivityStub = stub
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleActivityArgsForCall(i int) context.Context {
+	fake.loadSIPDispatchRuleActivityMutex.RLock()
+	defer fake.loadSIPDispatchRuleActivityMutex.RUnlock()
+	argsForCall := fake.loadSIPDispatchRuleActivityArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleActivityReturns(result1 map[string]*service.SIPDispatchRuleActivity, result2 error) {
+	fake.loadSIPDispatchRuleActivityMutex.Lock()
+	defer fake.loadSIPDispatchRuleActivityMutex.Unlock()
+	fake.LoadSIPDispatchRuleActivityStub = nil
+	fake.loadSIPDispatchRuleActivityReturns = struct {
+		result1 map[string]*service.SIPDispatchRuleActivity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPDispatchRuleActivityReturnsOnCall(i int, result1 map[string]*service.SIPDispatchRuleActivity, result2 error) {
+	fake.loadSIPDispatchRuleActivityMutex.Lock()
+	defer fake.loadSIPDispatchRuleActivityMutex.Unlock()
+	fake.LoadSIPDispatchRuleActivityStub = nil
+	if fake.loadSIPDispatchRuleActivityReturnsOnCall == nil {
+		fake.loadSIPDispatchRuleActivityReturnsOnCall = make(map[int]struct {
+			result1 map[string]*service.SIPDispatchRuleActivity
+			result2 error
+		})
+	}
+	fake.loadSIPDispatchRuleActivityReturnsOnCall[i] = struct {
+		result1 map[string]*service.SIPDispatchRuleActivity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipant(arg1 context.Context, arg2 string) (*livekit.SIPParticipantInfo, error) {
+	fake.loadSIPParticipantMutex.Lock()
+	ret, specificReturn := fake.loadSIPParticipantReturnsOnCall[len(fake.loadSIPParticipantArgsForCall)]
+	fake.loadSIPParticipantArgsForCall = append(fake.loadSIPParticipantArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.LoadSIPParticipantStub
+	fakeReturns := fake.loadSIPParticipantReturns
+	fake.recordInvocation("LoadSIPParticipant", []interface{}{arg1, arg2})
+	fake.loadSIPParticipantMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantCallCount() int {
+	fake.loadSIPParticipantMutex.RLock()
+	defer fake.loadSIPParticipantMutex.RUnlock()
+	return len(fake.loadSIPParticipantArgsForCall)
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantCalls(stub func(context.Context, string) (*livekit.SIPParticipantInfo, error)) {
+	fake.loadSIPParticipantMutex.Lock()
+	defer fake.loadSIPParticipantMutex.Unlock()
+	fake.LoadSIPParticipantStub = stub
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantArgsForCall(i int) (context.Context, string) {
+	fake.loadSIPParticipantMutex.RLock()
+	defer fake.loadSIPParticipantMutex.RUnlock()
+	argsForCall := fake.loadSIPParticipantArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantReturns(result1 *livekit.SIPParticipantInfo, result2 error) {
+	fake.loadSIPParticipantMutex.Lock()
+	defer fake.loadSIPParticipantMutex.Unlock()
+	fake.LoadSIPParticipantStub = nil
+	fake.loadSIPParticipantReturns = struct {
+		result1 *livekit.SIPParticipantInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantReturnsOnCall(i int, result1 *livekit.SIPParticipantInfo, result2 error) {
+	fake.loadSIPParticipantMutex.Lock()
+	defer fake.loadSIPParticipantMutex.Unlock()
+	fake.LoadSIPParticipantStub = nil
+	if fake.loadSIPParticipantReturnsOnCall == nil {
+		fake.loadSIPParticipantReturnsOnCall = make(map[int]struct {
+			result1 *livekit.SIPParticipantInfo
+			result2 error
+		})
+	}
+	fake.loadSIPParticipantReturnsOnCall[i] = struct {
+		result1 *livekit.SIPParticipantInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipants(arg1 context.Context, arg2 []string) ([]*livekit.SIPParticipantInfo, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.loadSIPParticipantsMutex.Lock()
+	ret, specificReturn := fake.loadSIPParticipantsReturnsOnCall[len(fake.loadSIPParticipantsArgsForCall)]
+	fake.loadSIPParticipantsArgsForCall = append(fake.loadSIPParticipantsArgsForCall, struct {
+		arg1 context.Context
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.LoadSIPParticipantsStub
+	fakeReturns := fake.loadSIPParticipantsReturns
+	fake.recordInvocation("LoadSIPParticipants", []interface{}{arg1, arg2Copy})
+	fake.loadSIPParticipantsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantsCallCount() int {
+	fake.loadSIPParticipantsMutex.RLock()
+	defer fake.loadSIPParticipantsMutex.RUnlock()
+	return len(fake.loadSIPParticipantsArgsForCall)
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantsCalls(stub func(context.Context, []string) ([]*livekit.SIPParticipantInfo, error)) {
+	fake.loadSIPParticipantsMutex.Lock()
+	defer fake.loadSIPParticipantsMutex.Unlock()
+	fake.LoadSIPParticipantsStub = stub
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantsArgsForCall(i int) (context.Context, []string) {
+	fake.loadSIPParticipantsMutex.RLock()
+	defer fake.loadSIPParticipantsMutex.RUnlock()
+	argsForCall := fake.loadSIPParticipantsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantsReturns(result1 []*livekit.SIPParticipantInfo, result2 error) {
+	fake.loadSIPParticipantsMutex.Lock()
+	defer fake.loadSIPParticipantsMutex.Unlock()
+	fake.LoadSIPParticipantsStub = nil
+	fake.loadSIPParticipantsReturns = struct {
+		result1 []*livekit.SIPParticipantInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPParticipantsReturnsOnCall(i int, result1 []*livekit.SIPParticipantInfo, result2 error) {
+	fake.loadSIPParticipantsMutex.Lock()
+	defer fake.loadSIPParticipantsMutex.Unlock()
+	fake.LoadSIPParticipantsStub = nil
+	if fake.loadSIPParticipantsReturnsOnCall == nil {
+		fake.loadSIPParticipantsReturnsOnCall = make(map[int]struct {
+			result1 []*livekit.SIPParticipantInfo
+			result2 error
+		})
+	}
+	fake.loadSIPParticipantsReturnsOnCall[i] = struct {
+		result1 []*livekit.SIPParticipantInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPTrunk(arg1 context.Context, arg2 string) (*livekit.SIPTrunkInfo, error) {
+	fake.loadSIPTrunkMutex.Lock()
+	ret, specificReturn := fake.loadSIPTrunkReturnsOnCall[len(fake.loadSIPTrunkArgsForCall)]
+	fake.loadSIPTrunkArgsForCall = append(fake.loadSIPTrunkArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.LoadSIPTrunkStub
+	fakeReturns := fake.loadSIPTrunkReturns
+	fake.recordInvocation("LoadSIPTrunk", []interface{}{arg1, arg2})
+	fake.loadSIPTrunkMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSIPStore) LoadSIPTrunkCallCount() int {
+	fake.loadSIPTrunkMutex.RLock()
+	defer fake.loadSIPTrunkMutex.RUnlock()
+	return len(fake.loadSIPTrunkArgsForCall)
+}
+
+func (fake *FakeSIPStore) LoadSIPTrunkCalls(stub func(context.Context, string) (*livekit.SIPTrunkInfo, error)) {
+	fake.loadSIPTrunkMutex.Lock()
+	defer fake.loadSIPTrunkMutex.Unlock()
+	fake.LoadSIPTrunkStub = stub
+}
+
+func (fake *FakeSIPStore) LoadSIPTrunkArgsForCall(i int) (context.Context, string) {
+	fake.loadSIPTrunkMutex.RLock()
+	defer fake.loadSIPTrunkMutex.RUnlock()
+	argsForCall := fake.loadSIPTrunkArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) LoadSIPTrunkReturns(result1 *livekit.SIPTrunkInfo, result2 error) {
+	fake.loadSIPTrunkMutex.Lock()
+	defer fake.loadSIPTrunkMutex.Unlock()
+	fake.LoadSIPTrunkStub = nil
+	fake.loadSIPTrunkReturns = struct {
+		result1 *livekit.SIPTrunkInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) LoadSIPTrunkReturnsOnCall(i int, result1 *livekit.SIPTrunkInfo, result2 error) {
+	fake.loadSIPTrunkMutex.Lock()
+	defer fake.loadSIPTrunkMutex.Unlock()
+	fake.LoadSIPTrunkStub = nil
+	if fake.loadSIPTrunkReturnsOnCall == nil {
+		fake.loadSIPTrunkReturnsOnCall = make(map[int]struct {
+			result1 *livekit.SIPTrunkInfo
+			result2 error
+		})
+	}
+	fake.loadSIPTrunkReturnsOnCall[i] = struct {
+		result1 *livekit.SIPTrunkInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSIPStore) MarkSIPDispatchRulesSeen(arg1 context.Context, arg2 []string, arg3 time.Time) error {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.markSIPDispatchRulesSeenMutex.Lock()
+	ret, specificReturn := fake.markSIPDispatchRulesSeenReturnsOnCall[len(fake.markSIPDispatchRulesSeenArgsForCall)]
+	fake.markSIPDispatchRulesSeenArgsForCall = append(fake.markSIPDispatchRulesSeenArgsForCall, struct {
+		arg1 context.Context
+		arg2 []string
+		arg3 time.Time
+	}{arg1, arg2Copy, arg3})
+	stub := fake.MarkSIPDispatchRulesSeenStub
+	fakeReturns := fake.markSIPDispatchRulesSeenReturns
+	fake.recordInvocation("MarkSIPDispatchRulesSeen", []interface{}{arg1, arg2Copy, arg3})
+	fake.markSIPDispatchRulesSeenMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) MarkSIPDispatchRulesSeenCallCount() int {
+	fake.markSIPDispatchRulesSeenMutex.RLock()
+	defer fake.markSIPDispatchRulesSeenMutex.RUnlock()
+	return len(fake.markSIPDispatchRulesSeenArgsForCall)
+}
+
+func (fake *FakeSIPStore) MarkSIPDispatchRulesSeenCalls(stub func(context.Context, []string, time.Time) error) {
+	fake.markSIPDispatchRulesSeenMutex.Lock()
+	defer fake.markSIPDispatchRulesSeenMutex.Unlock()
+	fake.MarkSIPDispatchRulesSeenStub = stub
+}
+
+func (fake *FakeSIPStore) MarkSIPDispatchRulesSeenArgsForCall(i int) (context.Context, []string, time.Time) {
+	fake.markSIPDispatchRulesSeenMutex.RLock()
+	defer fake.markSIPDispatchRulesSeenMutex.RUnlock()
+	argsForCall := fake.markSIPDispatchRulesSeenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeSIPStore) MarkSIPDispatchRulesSeenReturns(result1 error) {
+	fake.markSIPDispatchRulesSeenMutex.Lock()
+	defer fake.markSIPDispatchRulesSeenMutex.Unlock()
+	fake.MarkSIPDispatchRulesSeenStub = nil
+	fake.markSIPDispatchRulesSeenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) MarkSIPDispatchRulesSeenReturnsOnCall(i int, result1 error) {
+	fake.markSIPDispatchRulesSeenMutex.Lock()
+	defer fake.markSIPDispatchRulesSeenMutex.Unlock()
+	fake.MarkSIPDispatchRulesSeenStub = nil
+	if fake.markSIPDispatchRulesSeenReturnsOnCall == nil {
+		fake.markSIPDispatchRulesSeenReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.markSIPDispatchRulesSeenReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) RecordSIPDispatchRuleMatch(arg1 context.Context, arg2 string, arg3 time.Time) error {
+	fake.recordSIPDispatchRuleMatchMutex.Lock()
+	ret, specificReturn := fake.recordSIPDispatchRuleMatchReturnsOnCall[len(fake.recordSIPDispatchRuleMatchArgsForCall)]
+	fake.recordSIPDispatchRuleMatchArgsForCall = append(fake.recordSIPDispatchRuleMatchArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	stub := fake.RecordSIPDispatchRuleMatchStub
+	fakeReturns := fake.recordSIPDispatchRuleMatchReturns
+	fake.recordInvocation("RecordSIPDispatchRuleMatch", []interface{}{arg1, arg2, arg3})
+	fake.recordSIPDispatchRuleMatchMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) RecordSIPDispatchRuleMatchCallCount() int {
+	fake.recordSIPDispatchRuleMatchMutex.RLock()
+	defer fake.recordSIPDispatchRuleMatchMutex.RUnlock()
+	return len(fake.recordSIPDispatchRuleMatchArgsForCall)
+}
+
+func (fake *FakeSIPStore) RecordSIPDispatchRuleMatchCalls(stub func(context.Context, string, time.Time) error) {
+	fake.recordSIPDispatchRuleMatchMutex.Lock()
+	defer fake.recordSIPDispatchRuleMatchMutex.Unlock()
+	fake.RecordSIPDispatchRuleMatchStub = stub
+}
+
+func (fake *FakeSIPStore) RecordSIPDispatchRuleMatchArgsForCall(i int) (context.Context, string, time.Time) {
+	fake.recordSIPDispatchRuleMatchMutex.RLock()
+	defer fake.recordSIPDispatchRuleMatchMutex.RUnlock()
+	argsForCall := fake.recordSIPDispatchRuleMatchArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeSIPStore) RecordSIPDispatchRuleMatchReturns(result1 error) {
+	fake.recordSIPDispatchRuleMatchMutex.Lock()
+	defer fake.recordSIPDispatchRuleMatchMutex.Unlock()
+	fake.RecordSIPDispatchRuleMatchStub = nil
+	fake.recordSIPDispatchRuleMatchReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) RecordSIPDispatchRuleMatchReturnsOnCall(i int, result1 error) {
+	fake.recordSIPDispatchRuleMatchMutex.Lock()
+	defer fake.recordSIPDispatchRuleMatchMutex.Unlock()
+	fake.RecordSIPDispatchRuleMatchStub = nil
+	if fake.recordSIPDispatchRuleMatchReturnsOnCall == nil {
+		fake.recordSIPDispatchRuleMatchReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.recordSIPDispatchRuleMatchReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) RunSIPTxn(arg1 context.Context, arg2 func(tx service.SIPTxn) error) error {
+	fake.runSIPTxnMutex.Lock()
+	ret, specificReturn := fake.runSIPTxnReturnsOnCall[len(fake.runSIPTxnArgsForCall)]
+	fake.runSIPTxnArgsForCall = append(fake.runSIPTxnArgsForCall, struct {
+		arg1 context.Context
+		arg2 func(tx service.SIPTxn) error
+	}{arg1, arg2})
+	stub := fake.RunSIPTxnStub
+	fakeReturns := fake.runSIPTxnReturns
+	fake.recordInvocation("RunSIPTxn", []interface{}{arg1, arg2})
+	fake.runSIPTxnMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) RunSIPTxnCallCount() int {
+	fake.runSIPTxnMutex.RLock()
+	defer fake.runSIPTxnMutex.RUnlock()
+	return len(fake.runSIPTxnArgsForCall)
+}
+
+func (fake *FakeSIPStore) RunSIPTxnCalls(stub func(context.Context, func(tx service.SIPTxn) error) error) {
+	fake.runSIPTxnMutex.Lock()
+	defer fake.runSIPTxnMutex.Unlock()
+	fake.RunSIPTxnStub = stub
+}
+
+func (fake *FakeSIPStore) RunSIPTxnArgsForCall(i int) (context.Context, func(tx service.SIPTxn) error) {
+	fake.runSIPTxnMutex.RLock()
+	defer fake.runSIPTxnMutex.RUnlock()
+	argsForCall := fake.runSIPTxnArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) RunSIPTxnReturns(result1 error) {
+	fake.runSIPTxnMutex.Lock()
+	defer fake.runSIPTxnMutex.Unlock()
+	fake.RunSIPTxnStub = nil
+	fake.runSIPTxnReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) RunSIPTxnReturnsOnCall(i int, result1 error) {
+	fake.runSIPTxnMutex.Lock()
+	defer fake.runSIPTxnMutex.Unlock()
+	fake.RunSIPTxnStub = nil
+	if fake.runSIPTxnReturnsOnCall == nil {
+		fake.runSIPTxnReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.runSIPTxnReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) SetSIPDispatchRuleRoomMissing(arg1 context.Context, arg2 string, arg3 time.Time) error {
+	fake.setSIPDispatchRuleRoomMissingMutex.Lock()
+	ret, specificReturn := fake.setSIPDispatchRuleRoomMissingReturnsOnCall[len(fake.setSIPDispatchRuleRoomMissingArgsForCall)]
+	fake.setSIPDispatchRuleRoomMissingArgsForCall = append(fake.setSIPDispatchRuleRoomMissingArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	stub := fake.SetSIPDispatchRuleRoomMissingStub
+	fakeReturns := fake.setSIPDispatchRuleRoomMissingReturns
+	fake.recordInvocation("SetSIPDispatchRuleRoomMissing", []interface{}{arg1, arg2, arg3})
+	fake.setSIPDispatchRuleRoomMissingMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) SetSIPDispatchRuleRoomMissingCallCount() int {
+	fake.setSIPDispatchRuleRoomMissingMutex.RLock()
+	defer fake.setSIPDispatchRuleRoomMissingMutex.RUnlock()
+	return len(fake.setSIPDispatchRuleRoomMissingArgsForCall)
+}
+
+func (fake *FakeSIPStore) SetSIPDispatchRuleRoomMissingCalls(stub func(context.Context, string, time.Time) error) {
+	fake.setSIPDispatchRuleRoomMissingMutex.Lock()
+	defer fake.setSIPDispatchRuleRoomMissingMutex.Unlock()
+	fake.SetSIPDispatchRuleRoomMissingStub = stub
+}
+
+func (fake *FakeSIPStore) SetSIPDispatchRuleRoomMissingArgsForCall(i int) (context.Context, string, time.Time) {
+	fake.setSIPDispatchRuleRoomMissingMutex.RLock()
+	defer fake.setSIPDispatchRuleRoomMissingMutex.RUnlock()
+	argsForCall := fake.setSIPDispatchRuleRoomMissingArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeSIPStore) SetSIPDispatchRuleRoomMissingReturns(result1 error) {
+	fake.setSIPDispatchRuleRoomMissingMutex.Lock()
+	defer fake.setSIPDispatchRuleRoomMissingMutex.Unlock()
+	fake.SetSIPDispatchRuleRoomMissingStub = nil
+	fake.setSIPDispatchRuleRoomMissingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) SetSIPDispatchRuleRoomMissingReturnsOnCall(i int, result1 error) {
+	fake.setSIPDispatchRuleRoomMissingMutex.Lock()
+	defer fake.setSIPDispatchRuleRoomMissingMutex.Unlock()
+	fake.SetSIPDispatchRuleRoomMissingStub = nil
+	if fake.setSIPDispatchRuleRoomMissingReturnsOnCall == nil {
+		fake.setSIPDispatchRuleRoomMissingReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setSIPDispatchRuleRoomMissingReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) StoreSIPDispatchRule(arg1 context.Context, arg2 *livekit.SIPDispatchRuleInfo) error {
+	fake.storeSIPDispatchRuleMutex.Lock()
+	ret, specificReturn := fake.storeSIPDispatchRuleReturnsOnCall[len(fake.storeSIPDispatchRuleArgsForCall)]
+	fake.storeSIPDispatchRuleArgsForCall = append(fake.storeSIPDispatchRuleArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.SIPDispatchRuleInfo
+	}{arg1, arg2})
+	stub := fake.StoreSIPDispatchRuleStub
+	fakeReturns := fake.storeSIPDispatchRuleReturns
+	fake.recordInvocation("StoreSIPDispatchRule", []interface{}{arg1, arg2})
+	fake.storeSIPDispatchRuleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) StoreSIPDispatchRuleCallCount() int {
+	fake.storeSIPDispatchRuleMutex.RLock()
+	defer fake.storeSIPDispatchRuleMutex.RUnlock()
+	return len(fake.storeSIPDispatchRuleArgsForCall)
+}
+
+func (fake *FakeSIPStore) StoreSIPDispatchRuleCalls(stub func(context.Context, *livekit.SIPDispatchRuleInfo) error) {
+	fake.storeSIPDispatchRuleMutex.Lock()
+	defer fake.storeSIPDispatchRuleMutex.Unlock()
+	fake.StoreSIPDispatchRuleStub = stub
+}
+
+func (fake *FakeSIPStore) StoreSIPDispatchRuleArgsForCall(i int) (context.Context, *livekit.SIPDispatchRuleInfo) {
+	fake.storeSIPDispatchRuleMutex.RLock()
+	defer fake.storeSIPDispatchRuleMutex.RUnlock()
+	argsForCall := fake.storeSIPDispatchRuleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) StoreSIPDispatchRuleReturns(result1 error) {
+	fake.storeSIPDispatchRuleMutex.Lock()
+	defer fake.storeSIPDispatchRuleMutex.Unlock()
+	fake.StoreSIPDispatchRuleStub = nil
+	fake.storeSIPDispatchRuleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) StoreSIPDispatchRuleReturnsOnCall(i int, result1 error) {
+	fake.storeSIPDispatchRuleMutex.Lock()
+	defer fake.storeSIPDispatchRuleMutex.Unlock()
+	fake.StoreSIPDispatchRuleStub = nil
+	if fake.storeSIPDispatchRuleReturnsOnCall == nil {
+		fake.storeSIPDispatchRuleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeSIPDispatchRuleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) StoreSIPParticipant(arg1 context.Context, arg2 *livekit.SIPParticipantInfo) error {
+	fake.storeSIPParticipantMutex.Lock()
+	ret, specificReturn := fake.storeSIPParticipantReturnsOnCall[len(fake.storeSIPParticipantArgsForCall)]
+	fake.storeSIPParticipantArgsForCall = append(fake.storeSIPParticipantArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.SIPParticipantInfo
+	}{arg1, arg2})
+	stub := fake.StoreSIPParticipantStub
+	fakeReturns := fake.storeSIPParticipantReturns
+	fake.recordInvocation("StoreSIPParticipant", []interface{}{arg1, arg2})
+	fake.storeSIPParticipantMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) StoreSIPParticipantCallCount() int {
+	fake.storeSIPParticipantMutex.RLock()
+	defer fake.storeSIPParticipantMutex.RUnlock()
+	return len(fake.storeSIPParticipantArgsForCall)
+}
+
+func (fake *FakeSIPStore) StoreSIPParticipantCalls(stub func(context.Context, *livekit.SIPParticipantInfo) error) {
+	fake.storeSIPParticipantMutex.Lock()
+	defer fake.storeSIPParticipantMutex.Unlock()
+	fake.StoreSIPParticipantStub = stub
+}
+
+func (fake *FakeSIPStore) StoreSIPParticipantArgsForCall(i int) (context.Context, *livekit.SIPParticipantInfo) {
+	fake.storeSIPParticipantMutex.RLock()
+	defer fake.storeSIPParticipantMutex.RUnlock()
+	argsForCall := fake.storeSIPParticipantArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) StoreSIPParticipantReturns(result1 error) {
+	fake.storeSIPParticipantMutex.Lock()
+	defer fake.storeSIPParticipantMutex.Unlock()
+	fake.StoreSIPParticipantStub = nil
+	fake.storeSIPParticipantReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) StoreSIPParticipantReturnsOnCall(i int, result1 error) {
+	fake.storeSIPParticipantMutex.Lock()
+	defer fake.storeSIPParticipantMutex.Unlock()
+	fake.StoreSIPParticipantStub = nil
+	if fake.storeSIPParticipantReturnsOnCall == nil {
+		fake.storeSIPParticipantReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeSIPParticipantReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) StoreSIPScheduledCall(arg1 context.Context, arg2 *service.SIPScheduledCall) error {
+	fake.storeSIPScheduledCallMutex.Lock()
+	ret, specificReturn := fake.storeSIPScheduledCallReturnsOnCall[len(fake.storeSIPScheduledCallArgsForCall)]
+	fake.storeSIPScheduledCallArgsForCall = append(fake.storeSIPScheduledCallArgsForCall, struct {
+		arg1 context.Context
+		arg2 *service.SIPScheduledCall
+	}{arg1, arg2})
+	stub := fake.StoreSIPScheduledCallStub
+	fakeReturns := fake.storeSIPScheduledCallReturns
+	fake.recordInvocation("StoreSIPScheduledCall", []interface{}{arg1, arg2})
+	fake.storeSIPScheduledCallMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) StoreSIPScheduledCallCallCount() int {
+	fake.storeSIPScheduledCallMutex.RLock()
+	defer fake.storeSIPScheduledCallMutex.RUnlock()
+	return len(fake.storeSIPScheduledCallArgsForCall)
+}
+
+func (fake *FakeSIPStore) StoreSIPScheduledCallCalls(stub func(context.Context, *service.SIPScheduledCall) error) {
+	fake.storeSIPScheduledCallMutex.Lock()
+	defer fake.storeSIPScheduledCallMutex.Unlock()
+	fake.StoreSIPScheduledCallStub = stub
+}
+
+func (fake *FakeSIPStore) StoreSIPScheduledCallArgsForCall(i int) (context.Context, *service.SIPScheduledCall) {
+	fake.storeSIPScheduledCallMutex.RLock()
+	defer fake.storeSIPScheduledCallMutex.RUnlock()
+	argsForCall := fake.storeSIPScheduledCallArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) StoreSIPScheduledCallReturns(result1 error) {
+	fake.storeSIPScheduledCallMutex.Lock()
+	defer fake.storeSIPScheduledCallMutex.Unlock()
+	fake.StoreSIPScheduledCallStub = nil
+	fake.storeSIPScheduledCallReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) StoreSIPScheduledCallReturnsOnCall(i int, result1 error) {
+	fake.storeSIPScheduledCallMutex.Lock()
+	defer fake.storeSIPScheduledCallMutex.Unlock()
+	fake.StoreSIPScheduledCallStub = nil
+	if fake.storeSIPScheduledCallReturnsOnCall == nil {
+		fake.storeSIPScheduledCallReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeSIPScheduledCallReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) StoreSIPTrunk(arg1 context.Context, arg2 *livekit.SIPTrunkInfo) error {
+	fake.storeSIPTrunkMutex.Lock()
+	ret, specificReturn := fake.storeSIPTrunkReturnsOnCall[len(fake.storeSIPTrunkArgsForCall)]
+	fake.storeSIPTrunkArgsForCall = append(fake.storeSIPTrunkArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.SIPTrunkInfo
+	}{arg1, arg2})
+	stub := fake.StoreSIPTrunkStub
+	fakeReturns := fake.storeSIPTrunkReturns
+	fake.recordInvocation("StoreSIPTrunk", []interface{}{arg1, arg2})
+	fake.storeSIPTrunkMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSIPStore) StoreSIPTrunkCallCount() int {
+	fake.storeSIPTrunkMutex.RLock()
+	defer fake.storeSIPTrunkMutex.RUnlock()
+	return len(fake.storeSIPTrunkArgsForCall)
+}
+
+func (fake *FakeSIPStore) StoreSIPTrunkCalls(stub func(context.Context, *livekit.SIPTrunkInfo) error) {
+	fake.storeSIPTrunkMutex.Lock()
+	defer fake.storeSIPTrunkMutex.Unlock()
+	fake.StoreSIPTrunkStub = stub
+}
+
+func (fake *FakeSIPStore) StoreSIPTrunkArgsForCall(i int) (context.Context, *livekit.SIPTrunkInfo) {
+	fake.storeSIPTrunkMutex.RLock()
+	defer fake.storeSIPTrunkMutex.RUnlock()
+	argsForCall := fake.storeSIPTrunkArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSIPStore) StoreSIPTrunkReturns(result1 error) {
+	fake.storeSIPTrunkMutex.Lock()
+	defer fake.storeSIPTrunkMutex.Unlock()
+	fake.StoreSIPTrunkStub = nil
+	fake.storeSIPTrunkReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) StoreSIPTrunkReturnsOnCall(i int, result1 error) {
+	fake.storeSIPTrunkMutex.Lock()
+	defer fake.storeSIPTrunkMutex.Unlock()
+	fake.StoreSIPTrunkStub = nil
+	if fake.storeSIPTrunkReturnsOnCall == nil {
+		fake.storeSIPTrunkReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeSIPTrunkReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSIPStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeSIPStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ service.SIPStore = new(FakeSIPStore)
@@ -17,15 +17,30 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
 
 	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	sutils "github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/rpc"
 	"github.com/livekit/protocol/utils"
 	"github.com/livekit/psrpc"
 )
 
+const (
+	sipCleanupTimeout = 5 * time.Second
+	sipMaxBatchGet    = 100
+	sipMaxBatchCreate = 100
+)
+
 type SIPService struct {
 	conf        *config.SIPConfig
 	nodeID      livekit.NodeID
@@ -33,6 +48,16 @@ type SIPService struct {
 	psrpcClient rpc.SIPClient
 	store       SIPStore
 	roomService livekit.RoomService
+	dialQueue   *sipDialQueue
+	dtmfLimiter *sipRateLimiter
+	listBudget  *sipStoreBudget
+	status      sipStatusCache
+
+	// scheduled call dialing
+	workerCtx   context.Context
+	stopWorkers context.CancelFunc
+	workers     sync.WaitGroup
+	dialSlots   chan struct{}
 }
 
 func NewSIPService(
@@ -44,6 +69,7 @@ func NewSIPService(
 	rs livekit.RoomService,
 	ts telemetry.TelemetryService,
 ) *SIPService {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
 	return &SIPService{
 		conf:        conf,
 		nodeID:      nodeID,
@@ -51,15 +77,88 @@ func NewSIPService(
 		psrpcClient: psrpcClient,
 		store:       store,
 		roomService: rs,
+		dialQueue:   newSIPDialQueue(conf),
+		dtmfLimiter: newSIPRateLimiter(conf.DTMFRateLimit, conf.DTMFBurst),
+		listBudget:  newSIPStoreBudget(sipStorePathList, conf.MaxStoreListOps, false),
+		workerCtx:   workerCtx,
+		stopWorkers: stopWorkers,
+		dialSlots:   make(chan struct{}, sipScheduledCallDials),
 	}
 }
 
 func (s *SIPService) CreateSIPTrunk(ctx context.Context, req *livekit.CreateSIPTrunkRequest) (*livekit.SIPTrunkInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+
+	if problems := sipValidateTrunkFields(req); len(problems) != 0 {
+		return nil, sipTrunkProblemsError(problems)
+	}
+
+	info := newSIPTrunkInfo(req)
+
+	// conflicts and the quota are checked in the same transaction, so that concurrent creates cannot bypass them
+	err := s.store.RunSIPTxn(ctx, func(tx SIPTxn) error {
+		// a retried transaction finds the trunk if an earlier attempt was applied
+		if existing, err := tx.LoadSIPTrunk(ctx, info.SipTrunkId); err == nil && existing != nil {
+			return nil
+		}
+		trunks, err := tx.ListSIPTrunk(ctx)
+		if err != nil {
+			return err
+		}
+		if err := sipTrunkProblemsError(sipTrunkConflicts(req, "", trunks)); err != nil {
+			return err
+		}
+		if err := s.checkTrunkQuota(ctx, tx, 1); err != nil {
+			return err
+		}
+		return tx.StoreSIPTrunk(ctx, info)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// UpdateSIPTrunkInboundNumbersRegex replaces the inbound number patterns of a trunk. All patterns are validated first,
+// and the trunk is stored once, so inbound calls see either the old or the new set. Calls evaluated after the update
+// returns use the new set.
+func (s *SIPService) UpdateSIPTrunkInboundNumbersRegex(ctx context.Context, sipTrunkID string, patterns []string) (*livekit.SIPTrunkInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
-	info := &livekit.SIPTrunkInfo{
+	if err := sipValidateID("sip_trunk_id", sipTrunkID, utils.SIPTrunkPrefix); err != nil {
+		return nil, err
+	}
+	if problems := sipValidateTrunkFields(&livekit.CreateSIPTrunkRequest{InboundNumbersRegex: patterns}); len(problems) != 0 {
+		return nil, sipTrunkProblemsError(problems)
+	}
+
+	var info *livekit.SIPTrunkInfo
+	err := s.store.RunSIPTxn(ctx, func(tx SIPTxn) error {
+		var err error
+		if info, err = tx.LoadSIPTrunk(ctx, sipTrunkID); err != nil {
+			return err
+		}
+		info.InboundNumbersRegex = patterns
+		return tx.StoreSIPTrunk(ctx, info)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func newSIPTrunkInfo(req *livekit.CreateSIPTrunkRequest) *livekit.SIPTrunkInfo {
+	return &livekit.SIPTrunkInfo{
 		SipTrunkId:          utils.NewGuid(utils.SIPTrunkPrefix),
 		InboundAddresses:    req.InboundAddresses,
 		OutboundAddress:     req.OutboundAddress,
@@ -68,79 +167,183 @@ func (s *SIPService) CreateSIPTrunk(ctx context.Context, req *livekit.CreateSIPT
 		Username:            req.Username,
 		Password:            req.Password,
 	}
-
-	if err := s.store.StoreSIPTrunk(ctx, info); err != nil {
-		return nil, err
-	}
-	return info, nil
 }
 
 func (s *SIPService) ListSIPTrunk(ctx context.Context, req *livekit.ListSIPTrunkRequest) (*livekit.ListSIPTrunkResponse, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
+	release, err := s.listBudget.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	trunks, err := s.store.ListSIPTrunk(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	sort.Slice(trunks, func(i, j int) bool { return trunks[i].SipTrunkId < trunks[j].SipTrunkId })
 	return &livekit.ListSIPTrunkResponse{Items: trunks}, nil
 }
 
 func (s *SIPService) DeleteSIPTrunk(ctx context.Context, req *livekit.DeleteSIPTrunkRequest) (*livekit.SIPTrunkInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
+	if err := sipValidateID("sip_trunk_id", req.SipTrunkId, utils.SIPTrunkPrefix); err != nil {
+		return nil, err
+	}
+
 	info, err := s.store.LoadSIPTrunk(ctx, req.SipTrunkId)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = s.store.DeleteSIPTrunk(ctx, info); err != nil {
+	switch s.conf.TrunkDeletePolicy {
+	case config.SIPTrunkDeleteBlock, config.SIPTrunkDeleteCascade:
+		// rules are checked in the same transaction, so that a rule created concurrently cannot be left behind
+		err = s.store.RunSIPTxn(ctx, func(tx SIPTxn) error {
+			if err := s.updateTrunkRules(ctx, tx, info.SipTrunkId); err != nil {
+				return err
+			}
+			return tx.DeleteSIPTrunk(ctx, info)
+		})
+	default:
+		err = s.store.DeleteSIPTrunk(ctx, info)
+	}
+	if err != nil {
 		return nil, err
 	}
 
 	return info, nil
 }
 
+// updateTrunkRules applies the trunk delete policy to the dispatch rules referencing a trunk.
+func (s *SIPService) updateTrunkRules(ctx context.Context, tx SIPTxn, sipTrunkID string) error {
+	rules, err := tx.ListSIPDispatchRule(ctx)
+	if err != nil {
+		return err
+	}
+
+	var used []string
+	for _, r := range rules {
+		if !slices.Contains(r.TrunkIds, sipTrunkID) {
+			continue
+		}
+		used = append(used, r.SipDispatchRuleId)
+		if s.conf.TrunkDeletePolicy != config.SIPTrunkDeleteCascade {
+			continue
+		}
+
+		r.TrunkIds = slices.DeleteFunc(r.TrunkIds, func(id string) bool { return id == sipTrunkID })
+		if len(r.TrunkIds) == 0 {
+			// a rule without trunks matches calls from every trunk, which is not what it was meant for
+			err = tx.DeleteSIPDispatchRule(ctx, r)
+		} else {
+			err = tx.StoreSIPDispatchRule(ctx, r)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(used) != 0 && s.conf.TrunkDeletePolicy == config.SIPTrunkDeleteBlock {
+		sort.Strings(used)
+		return fmt.Errorf("%w: %s", ErrSIPTrunkInUse, strings.Join(used, ", "))
+	}
+	return nil
+}
+
 func (s *SIPService) CreateSIPDispatchRule(ctx context.Context, req *livekit.CreateSIPDispatchRuleRequest) (*livekit.SIPDispatchRuleInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
-	info := &livekit.SIPDispatchRuleInfo{
-		SipDispatchRuleId: utils.NewGuid(utils.SIPDispatchRulePrefix),
-		Rule:              req.Rule,
-		TrunkIds:          req.TrunkIds,
-		HidePhoneNumber:   req.HidePhoneNumber,
+	if err := sipValidateDispatchRuleRequest(req); err != nil {
+		return nil, err
 	}
 
-	if err := s.store.StoreSIPDispatchRule(ctx, info); err != nil {
+	info := newSIPDispatchRuleInfo(req)
+
+	err := s.store.RunSIPTxn(ctx, func(tx SIPTxn) error {
+		if existing, err := tx.LoadSIPDispatchRule(ctx, info.SipDispatchRuleId); err == nil && existing != nil {
+			return nil
+		}
+		if err := s.checkDispatchRuleQuota(ctx, tx, 1); err != nil {
+			return err
+		}
+		return tx.StoreSIPDispatchRule(ctx, info)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return info, nil
 }
 
+// sipValidateDispatchRuleRequest checks a new dispatch rule, normalizing the rule in place.
+func sipValidateDispatchRuleRequest(req *livekit.CreateSIPDispatchRuleRequest) error {
+	if err := sipNormalizeDispatchRule(req.Rule); err != nil {
+		return psrpc.NewError(psrpc.InvalidArgument, err)
+	}
+	return sipValidateTrunkIDs(req.TrunkIds)
+}
+
+func newSIPDispatchRuleInfo(req *livekit.CreateSIPDispatchRuleRequest) *livekit.SIPDispatchRuleInfo {
+	return &livekit.SIPDispatchRuleInfo{
+		SipDispatchRuleId: utils.NewGuid(utils.SIPDispatchRulePrefix),
+		Rule:              req.Rule,
+		TrunkIds:          req.TrunkIds,
+		HidePhoneNumber:   req.HidePhoneNumber,
+	}
+}
+
 func (s *SIPService) ListSIPDispatchRule(ctx context.Context, req *livekit.ListSIPDispatchRuleRequest) (*livekit.ListSIPDispatchRuleResponse, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
+	release, err := s.listBudget.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	rules, err := s.store.ListSIPDispatchRule(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	sort.Slice(rules, func(i, j int) bool { return rules[i].SipDispatchRuleId < rules[j].SipDispatchRuleId })
 	return &livekit.ListSIPDispatchRuleResponse{Items: rules}, nil
 }
 
 func (s *SIPService) DeleteSIPDispatchRule(ctx context.Context, req *livekit.DeleteSIPDispatchRuleRequest) (*livekit.SIPDispatchRuleInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
+	if err := sipValidateID("sip_dispatch_rule_id", req.SipDispatchRuleId, utils.SIPDispatchRulePrefix); err != nil {
+		return nil, err
+	}
+
 	info, err := s.store.LoadSIPDispatchRule(ctx, req.SipDispatchRuleId)
 	if err != nil {
 		return nil, err
@@ -154,54 +357,274 @@ func (s *SIPService) DeleteSIPDispatchRule(ctx context.Context, req *livekit.Del
 }
 
 func (s *SIPService) CreateSIPParticipant(ctx context.Context, req *livekit.CreateSIPParticipantRequest) (*livekit.SIPParticipantInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
+	if req.SipTrunkId != "" {
+		if err := sipValidateID("sip_trunk_id", req.SipTrunkId, utils.SIPTrunkPrefix); err != nil {
+			return nil, err
+		}
+	}
+	return s.createSIPParticipant(ctx, req)
+}
+
+func (s *SIPService) createSIPParticipant(ctx context.Context, req *livekit.CreateSIPParticipantRequest) (*livekit.SIPParticipantInfo, error) {
 	info := &livekit.SIPParticipantInfo{
 		SipParticipantId: utils.NewGuid(utils.SIPParticipantPrefix),
 	}
+	log := sipParticipantLogger(info.SipParticipantId).WithValues("sipTrunkID", req.SipTrunkId, "room", req.RoomName)
+
+	if s.conf.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.conf.DialTimeout)
+		defer cancel()
+	}
 
-	if err := s.store.StoreSIPParticipant(ctx, info); err != nil {
+	if req.SipTrunkId != "" {
+		// the trunk is checked before queueing, since the queue and its metrics are keyed by trunk ID
+		if _, err := s.store.LoadSIPTrunk(ctx, req.SipTrunkId); err != nil {
+			log.Warnw("could not dial SIP participant", err)
+			return nil, err
+		}
+	}
+
+	log.Debugw("queueing SIP dial")
+	release, err := s.dialQueue.Acquire(ctx, req.SipTrunkId)
+	if err != nil {
+		log.Warnw("could not dial SIP participant", err)
 		return nil, err
 	}
+	defer release()
+
+	if s.conf.MaxParticipants > 0 {
+		// the quota is checked in the same transaction, so that concurrent dials cannot exceed it
+		err = s.store.RunSIPTxn(ctx, func(tx SIPTxn) error {
+			// a retried transaction finds the participant if an earlier attempt was applied
+			if existing, err := tx.LoadSIPParticipant(ctx, info.SipParticipantId); err == nil && existing != nil {
+				return nil
+			}
+			participants, err := tx.ListSIPParticipant(ctx)
+			if err != nil {
+				return err
+			}
+			if len(participants) >= s.conf.MaxParticipants {
+				log.Infow("SIP participant quota exceeded", "max", s.conf.MaxParticipants)
+				return ErrSIPParticipantQuota
+			}
+			return tx.StoreSIPParticipant(ctx, info)
+		})
+		if err == ErrSIPParticipantQuota {
+			return nil, err
+		}
+	} else {
+		err = s.store.StoreSIPParticipant(ctx, info)
+	}
+	if ctx.Err() != nil {
+		// The caller gave up while the record was being written, so it may or may not exist.
+		log.Infow("SIP dial cancelled", "error", ctx.Err())
+		s.cleanupSIPParticipant(info, log)
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		log.Errorw("could not store SIP participant", err)
+		return nil, err
+	}
+	log.Infow("SIP participant created")
+	s.recordSIPParticipantEvent(ctx, info.SipParticipantId, SIPParticipantEventCreated, "room "+req.RoomName+", trunk "+req.SipTrunkId, log)
 	return info, nil
 }
 
+// cleanupSIPParticipant removes the record of a participant whose dial was abandoned.
+// It does not use the request context, since that is usually what was cancelled.
+func (s *SIPService) cleanupSIPParticipant(info *livekit.SIPParticipantInfo, log logger.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), sipCleanupTimeout)
+	defer cancel()
+	if err := s.store.DeleteSIPParticipant(ctx, info); err != nil {
+		log.Warnw("could not clean up SIP participant", err)
+	}
+}
+
 func (s *SIPService) ListSIPParticipant(ctx context.Context, req *livekit.ListSIPParticipantRequest) (*livekit.ListSIPParticipantResponse, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
+	release, err := s.listBudget.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	participants, err := s.store.ListSIPParticipant(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	sort.Slice(participants, func(i, j int) bool { return participants[i].SipParticipantId < participants[j].SipParticipantId })
 	return &livekit.ListSIPParticipantResponse{Items: participants}, nil
 }
 
+// BatchGetSIPParticipantResult holds the participants found by BatchGetSIPParticipant, in request order,
+// and the requested IDs that do not exist.
+type BatchGetSIPParticipantResult struct {
+	Items   []*livekit.SIPParticipantInfo
+	Missing []string
+}
+
+// BatchGetSIPParticipant loads several participants with a single store request.
+func (s *SIPService) BatchGetSIPParticipant(ctx context.Context, sipParticipantIDs []string) (*BatchGetSIPParticipantResult, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+	if len(sipParticipantIDs) > sipMaxBatchGet {
+		return nil, psrpc.NewErrorf(psrpc.InvalidArgument, "at most %d sip participants can be requested at once", sipMaxBatchGet)
+	}
+
+	ids := make([]string, 0, len(sipParticipantIDs))
+	seen := make(map[string]bool, len(sipParticipantIDs))
+	for _, id := range sipParticipantIDs {
+		if err := sipValidateID("sip_participant_id", id, utils.SIPParticipantPrefix); err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	infos, err := s.store.LoadSIPParticipants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*livekit.SIPParticipantInfo, len(infos))
+	for _, info := range infos {
+		found[info.SipParticipantId] = info
+	}
+	res := &BatchGetSIPParticipantResult{}
+	for _, id := range ids {
+		if info := found[id]; info != nil {
+			res.Items = append(res.Items, info)
+		} else {
+			res.Missing = append(res.Missing, id)
+		}
+	}
+	return res, nil
+}
+
 func (s *SIPService) DeleteSIPParticipant(ctx context.Context, req *livekit.DeleteSIPParticipantRequest) (*livekit.SIPParticipantInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
+	if err := sipValidateID("sip_participant_id", req.SipParticipantId, utils.SIPParticipantPrefix); err != nil {
+		return nil, err
+	}
+
+	log := sipParticipantLogger(req.SipParticipantId)
 	info, err := s.store.LoadSIPParticipant(ctx, req.SipParticipantId)
 	if err != nil {
 		return nil, err
 	}
 
 	if err = s.store.DeleteSIPParticipant(ctx, info); err != nil {
+		log.Errorw("could not delete SIP participant", err)
 		return nil, err
 	}
 
+	log.Infow("SIP participant deleted")
+	s.recordSIPParticipantEvent(ctx, info.SipParticipantId, SIPParticipantEventDeleted, "", log)
 	return info, nil
 }
 
 func (s *SIPService) SendSIPParticipantDTMF(ctx context.Context, req *livekit.SendSIPParticipantDTMFRequest) (*livekit.SIPParticipantDTMFInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
 	if s.store == nil {
 		return nil, ErrSIPNotConnected
 	}
 
+	if err := sipValidateID("sip_participant_id", req.SipParticipantId, utils.SIPParticipantPrefix); err != nil {
+		return nil, err
+	}
+	if !s.dtmfLimiter.Allow(req.SipParticipantId) {
+		sipParticipantLogger(req.SipParticipantId).Infow("SIP DTMF request rate limited")
+		prometheus.SIPDTMFRequest("rate_limited")
+		return nil, ErrSIPDTMFRateLimited
+	}
+	// sending is not implemented yet, so requests that pass the limiter are not counted as accepted
+	prometheus.SIPDTMFRequest("unsupported")
+	sipParticipantLogger(req.SipParticipantId).Debugw("SIP DTMF not supported", "digits", len(req.Digits))
+
 	return nil, fmt.Errorf("TODO")
 }
+
+// checkTrunkQuota returns an error if creating n more trunks would exceed the configured limit.
+func (s *SIPService) checkTrunkQuota(ctx context.Context, tx SIPTxn, n int) error {
+	if s.conf.MaxTrunks <= 0 {
+		return nil
+	}
+	trunks, err := tx.ListSIPTrunk(ctx)
+	if err != nil {
+		return err
+	}
+	if len(trunks)+n > s.conf.MaxTrunks {
+		return ErrSIPTrunkQuota
+	}
+	return nil
+}
+
+// checkDispatchRuleQuota returns an error if creating n more dispatch rules would exceed the configured limit.
+func (s *SIPService) checkDispatchRuleQuota(ctx context.Context, tx SIPTxn, n int) error {
+	if s.conf.MaxDispatchRules <= 0 {
+		return nil
+	}
+	rules, err := tx.ListSIPDispatchRule(ctx)
+	if err != nil {
+		return err
+	}
+	if len(rules)+n > s.conf.MaxDispatchRules {
+		return ErrSIPDispatchRuleQuota
+	}
+	return nil
+}
+
+func sipParticipantLogger(sipParticipantID string) logger.Logger {
+	return logger.GetLogger().WithComponent(sutils.ComponentSIP).WithValues("sipParticipantID", sipParticipantID)
+}
+
+// sipValidateID checks that an ID has the prefix of the object type it refers to,
+// so that IDs of other objects are rejected before reaching the store.
+func sipValidateID(field, id, prefix string) error {
+	if !strings.HasPrefix(id, prefix) || len(id) == len(prefix) {
+		return psrpc.NewErrorf(psrpc.InvalidArgument, "invalid %s %q: expected an ID starting with %s", field, id, prefix)
+	}
+	for _, c := range id[len(prefix):] {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return psrpc.NewErrorf(psrpc.InvalidArgument, "invalid %s %q: unexpected character %q", field, id, c)
+		}
+	}
+	return nil
+}
+
+func sipValidateTrunkIDs(ids []string) error {
+	for _, id := range ids {
+		if err := sipValidateID("trunk_ids", id, utils.SIPTrunkPrefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
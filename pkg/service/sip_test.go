@@ -0,0 +1,1157 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"github.com/urfave/negroni/v3"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/auth/authfakes"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/livekit-server/pkg/service/servicefakes"
+)
+
+func sipAdminContext() context.Context {
+	return service.WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{RoomCreate: true}})
+}
+
+func newTestSIPService(conf config.SIPConfig) (*service.SIPService, *servicefakes.FakeSIPStore) {
+	store := &servicefakes.FakeSIPStore{}
+	store.RunSIPTxnStub = func(ctx context.Context, fn func(tx service.SIPTxn) error) error {
+		return fn(store)
+	}
+	return service.NewSIPService(&conf, "node", nil, nil, store, nil, nil), store
+}
+
+func TestSendSIPParticipantDTMF(t *testing.T) {
+	t.Run("rate limited", func(t *testing.T) {
+		svc, _ := newTestSIPService(config.SIPConfig{DTMFRateLimit: 1, DTMFBurst: 2})
+		req := &livekit.SendSIPParticipantDTMFRequest{SipParticipantId: "SP_aaa", Digits: "1"}
+		for i := 0; i < 2; i++ {
+			_, err := svc.SendSIPParticipantDTMF(sipAdminContext(), req)
+			require.NotErrorIs(t, err, service.ErrSIPDTMFRateLimited)
+		}
+		_, err := svc.SendSIPParticipantDTMF(sipAdminContext(), req)
+		var perr psrpc.Error
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, psrpc.ResourceExhausted, perr.Code())
+
+		// other participants are not affected
+		_, err = svc.SendSIPParticipantDTMF(sipAdminContext(), &livekit.SendSIPParticipantDTMFRequest{SipParticipantId: "SP_bbb", Digits: "1"})
+		require.NotErrorIs(t, err, service.ErrSIPDTMFRateLimited)
+	})
+}
+
+func TestCreateSIPDispatchRule(t *testing.T) {
+	t.Run("room name is normalized", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		info, err := svc.CreateSIPDispatchRule(sipAdminContext(), &livekit.CreateSIPDispatchRuleRequest{
+			Rule: &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleDirect{
+				DispatchRuleDirect: &livekit.SIPDispatchRuleDirect{RoomName: " support "},
+			}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "support", info.Rule.GetDispatchRuleDirect().RoomName)
+		require.Equal(t, 1, store.StoreSIPDispatchRuleCallCount())
+	})
+
+	t.Run("invalid room name", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		_, err := svc.CreateSIPDispatchRule(sipAdminContext(), &livekit.CreateSIPDispatchRuleRequest{
+			Rule: &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleDirect{
+				DispatchRuleDirect: &livekit.SIPDispatchRuleDirect{RoomName: "  "},
+			}},
+		})
+		require.ErrorIs(t, err, service.ErrSIPInvalidRoomName)
+		var perr psrpc.Error
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, psrpc.InvalidArgument, perr.Code())
+		require.Equal(t, 0, store.StoreSIPDispatchRuleCallCount())
+	})
+
+	t.Run("missing rule", func(t *testing.T) {
+		svc, _ := newTestSIPService(config.SIPConfig{})
+		_, err := svc.CreateSIPDispatchRule(sipAdminContext(), &livekit.CreateSIPDispatchRuleRequest{})
+		require.Error(t, err)
+	})
+}
+
+func TestSIPConfigExportImport(t *testing.T) {
+	trunk := &livekit.SIPTrunkInfo{SipTrunkId: "ST_aaa", OutboundNumber: "+15550001111", Username: "user", Password: "secret"}
+	rule := &livekit.SIPDispatchRuleInfo{
+		SipDispatchRuleId: "SDR_aaa",
+		TrunkIds:          []string{"ST_aaa"},
+		Rule: &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleDirect{
+			DispatchRuleDirect: &livekit.SIPDispatchRuleDirect{RoomName: "support"},
+		}},
+	}
+
+	t.Run("export", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.ListSIPTrunkReturns([]*livekit.SIPTrunkInfo{trunk}, nil)
+		store.ListSIPDispatchRuleReturns([]*livekit.SIPDispatchRuleInfo{rule}, nil)
+
+		doc, err := svc.ExportSIPConfig(sipAdminContext(), true)
+		require.NoError(t, err)
+		require.Empty(t, doc.Trunks[0].Password)
+		require.Equal(t, "secret", trunk.Password)
+
+		data, err := json.Marshal(doc)
+		require.NoError(t, err)
+		var got service.SIPConfigDocument
+		require.NoError(t, json.Unmarshal(data, &got))
+		require.Len(t, got.Trunks, 1)
+		require.Equal(t, "+15550001111", got.Trunks[0].OutboundNumber)
+		require.Len(t, got.DispatchRules, 1)
+		require.Equal(t, "support", got.DispatchRules[0].Rule.GetDispatchRuleDirect().RoomName)
+	})
+
+	doc := &service.SIPConfigDocument{
+		Trunks:        []*livekit.SIPTrunkInfo{{SipTrunkId: "ST_aaa", OutboundNumber: "+15550001111", Username: "user"}},
+		DispatchRules: []*livekit.SIPDispatchRuleInfo{rule},
+	}
+
+	t.Run("import remaps ids", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		res, err := svc.ImportSIPConfig(sipAdminContext(), doc, service.SIPImportOptions{})
+		require.NoError(t, err)
+		require.Equal(t, service.SIPImportCreated, res.Trunks[0].Action)
+		require.NotEqual(t, "ST_aaa", res.Trunks[0].ID)
+
+		require.Equal(t, 1, store.StoreSIPDispatchRuleCallCount())
+		_, stored := store.StoreSIPDispatchRuleArgsForCall(0)
+		require.Equal(t, []string{res.Trunks[0].ID}, stored.TrunkIds)
+		require.Equal(t, res.DispatchRules[0].ID, stored.SipDispatchRuleId)
+		// the document is not modified
+		require.Equal(t, []string{"ST_aaa"}, rule.TrunkIds)
+	})
+
+	t.Run("import preserves ids and credentials", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.LoadSIPTrunkReturns(trunk, nil)
+		store.LoadSIPDispatchRuleReturns(nil, service.ErrSIPDispatchRuleNotFound)
+
+		res, err := svc.ImportSIPConfig(sipAdminContext(), doc, service.SIPImportOptions{PreserveIDs: true})
+		require.NoError(t, err)
+		require.Equal(t, service.SIPImportUpdated, res.Trunks[0].Action)
+		require.Equal(t, service.SIPImportCreated, res.DispatchRules[0].Action)
+		require.Equal(t, "SDR_aaa", res.DispatchRules[0].ID)
+
+		_, stored := store.StoreSIPTrunkArgsForCall(0)
+		require.Equal(t, "ST_aaa", stored.SipTrunkId)
+		require.Equal(t, "secret", stored.Password)
+	})
+
+	t.Run("import validates trunks", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.ListSIPTrunkReturns([]*livekit.SIPTrunkInfo{{SipTrunkId: "ST_old", OutboundNumber: "+15550002222"}}, nil)
+		res, err := svc.ImportSIPConfig(sipAdminContext(), &service.SIPConfigDocument{
+			Trunks: []*livekit.SIPTrunkInfo{
+				{SipTrunkId: "ST_aaa", OutboundNumber: "+15550001111"},
+				{SipTrunkId: "ST_bbb", OutboundNumber: "+15550001111"},
+				{SipTrunkId: "ST_ccc", OutboundNumber: "+15550002222"},
+				{SipTrunkId: "ST_ddd", InboundAddresses: []string{"not an address"}},
+			},
+			DispatchRules: []*livekit.SIPDispatchRuleInfo{rule, {
+				SipDispatchRuleId: "SDR_bbb",
+				TrunkIds:          []string{"ST_bbb"},
+				Rule:              rule.Rule,
+			}},
+		}, service.SIPImportOptions{})
+		require.NoError(t, err)
+		require.Equal(t, service.SIPImportCreated, res.Trunks[0].Action)
+		// conflicts with a trunk from the same document
+		require.Equal(t, service.SIPImportFailed, res.Trunks[1].Action)
+		// conflicts with a stored trunk
+		require.Equal(t, service.SIPImportFailed, res.Trunks[2].Action)
+		require.Equal(t, service.SIPImportFailed, res.Trunks[3].Action)
+		require.Equal(t, 1, store.StoreSIPTrunkCallCount())
+
+		require.Equal(t, service.SIPImportCreated, res.DispatchRules[0].Action)
+		// the rule is not stored with the source ID of a trunk that failed
+		require.Equal(t, service.SIPImportFailed, res.DispatchRules[1].Action)
+		require.Equal(t, 1, store.StoreSIPDispatchRuleCallCount())
+	})
+
+	t.Run("import is atomic", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.RunSIPTxnStub = func(ctx context.Context, fn func(tx service.SIPTxn) error) error {
+			// changes are made, but the transaction fails to commit
+			if err := fn(store); err != nil {
+				return err
+			}
+			return service.ErrSIPStoreConflict
+		}
+		_, err := svc.ImportSIPConfig(sipAdminContext(), doc, service.SIPImportOptions{})
+		require.ErrorIs(t, err, service.ErrSIPStoreConflict)
+		require.Equal(t, 1, store.RunSIPTxnCallCount())
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		bad := &livekit.SIPDispatchRuleInfo{SipDispatchRuleId: "SDR_bbb"}
+		res, err := svc.ImportSIPConfig(sipAdminContext(), &service.SIPConfigDocument{
+			Trunks:        doc.Trunks,
+			DispatchRules: []*livekit.SIPDispatchRuleInfo{rule, bad},
+		}, service.SIPImportOptions{DryRun: true})
+		require.NoError(t, err)
+		require.Equal(t, service.SIPImportCreated, res.DispatchRules[0].Action)
+		require.Equal(t, service.SIPImportFailed, res.DispatchRules[1].Action)
+		require.Error(t, res.DispatchRules[1].Error)
+		require.Equal(t, 0, store.StoreSIPTrunkCallCount())
+		require.Equal(t, 0, store.StoreSIPDispatchRuleCallCount())
+	})
+}
+
+func TestGetSIPStatus(t *testing.T) {
+	t.Run("not connected", func(t *testing.T) {
+		conf := config.SIPConfig{}
+		svc := service.NewSIPService(&conf, "node", nil, nil, nil, nil, nil)
+		st := svc.GetSIPStatus(sipAdminContext())
+		require.False(t, st.StoreConnected)
+		require.Equal(t, service.SIPStatusDisabled, st.Status)
+		require.NotEmpty(t, st.Warnings)
+
+		w := httptest.NewRecorder()
+		svc.ServeStatus(w, httptest.NewRequest(http.MethodGet, "/sip/status", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"status": "disabled"}`, w.Body.String())
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.ListSIPTrunkReturns([]*livekit.SIPTrunkInfo{{SipTrunkId: "ST_aaa"}}, nil)
+		store.ListSIPDispatchRuleReturns(nil, errors.New("timeout"))
+		store.ListSIPParticipantReturns([]*livekit.SIPParticipantInfo{{}, {}}, nil)
+
+		st := svc.GetSIPStatus(sipAdminContext())
+		require.True(t, st.StoreConnected)
+		require.Equal(t, 1, st.Trunks)
+		require.Equal(t, 2, st.Participants)
+		require.Len(t, st.Warnings, 1)
+		require.Equal(t, service.SIPStatusDown, st.Status)
+
+		// cached
+		svc.GetSIPStatus(sipAdminContext())
+		require.Equal(t, 1, store.ListSIPTrunkCallCount())
+	})
+
+	t.Run("concurrent requests share one refresh", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		release := make(chan struct{})
+		store.ListSIPTrunkStub = func(ctx context.Context) ([]*livekit.SIPTrunkInfo, error) {
+			<-release
+			return nil, nil
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				svc.GetSIPStatus(sipAdminContext())
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		require.Equal(t, 1, store.ListSIPTrunkCallCount())
+	})
+
+	t.Run("serve", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.ListSIPTrunkReturns([]*livekit.SIPTrunkInfo{{SipTrunkId: "ST_aaa"}}, nil)
+
+		// counts require admin
+		w := httptest.NewRecorder()
+		svc.ServeStatus(w, httptest.NewRequest(http.MethodGet, "/sip/status", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"status": "up"}`, w.Body.String())
+
+		w = httptest.NewRecorder()
+		svc.ServeStatus(w, httptest.NewRequest(http.MethodGet, "/sip/status", nil).WithContext(sipAdminContext()))
+		require.Equal(t, http.StatusOK, w.Code)
+		var st service.SIPStatus
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &st))
+		require.Equal(t, service.SIPStatusUp, st.Status)
+		require.Equal(t, 1, st.Trunks)
+
+		svc, store = newTestSIPService(config.SIPConfig{})
+		store.ListSIPTrunkReturns(nil, errors.New("timeout"))
+		w = httptest.NewRecorder()
+		svc.ServeStatus(w, httptest.NewRequest(http.MethodGet, "/sip/status", nil))
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.JSONEq(t, `{"status": "down"}`, w.Body.String())
+	})
+}
+
+func TestValidateSIPTrunk(t *testing.T) {
+	svc, store := newTestSIPService(config.SIPConfig{})
+	store.ListSIPTrunkReturns([]*livekit.SIPTrunkInfo{
+		{SipTrunkId: "ST_aaa", OutboundNumber: "+15550001111", InboundAddresses: []string{"10.0.0.0/8"}},
+	}, nil)
+
+	t.Run("valid", func(t *testing.T) {
+		problems, err := svc.ValidateSIPTrunk(sipAdminContext(), &livekit.CreateSIPTrunkRequest{
+			InboundAddresses:    []string{"192.168.0.1", "172.16.0.0/12"},
+			OutboundAddress:     "sip.example.com:5060",
+			OutboundNumber:      "+15550001111",
+			InboundNumbersRegex: []string{`^\+1555\d+$`},
+		})
+		require.NoError(t, err)
+		require.Empty(t, problems)
+	})
+
+	t.Run("all problems reported", func(t *testing.T) {
+		problems, err := svc.ValidateSIPTrunk(sipAdminContext(), &livekit.CreateSIPTrunkRequest{
+			InboundAddresses:    []string{"10.0.0.0/8", "not an ip"},
+			OutboundAddress:     "sip://example.com",
+			OutboundNumber:      "+15550001111",
+			InboundNumbersRegex: []string{`(`},
+		})
+		require.NoError(t, err)
+		var fields []string
+		for _, p := range problems {
+			fields = append(fields, p.Field)
+		}
+		require.ElementsMatch(t, []string{"inbound_addresses", "outbound_address", "inbound_numbers_regex"}, fields)
+
+		problems, err = svc.ValidateSIPTrunk(sipAdminContext(), &livekit.CreateSIPTrunkRequest{
+			InboundAddresses: []string{"10.0.0.0/8"},
+			OutboundNumber:   "+15550001111",
+		})
+		require.NoError(t, err)
+		require.Len(t, problems, 1)
+		require.Equal(t, "outbound_number", problems[0].Field)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.ListSIPTrunkReturns([]*livekit.SIPTrunkInfo{
+			{SipTrunkId: "ST_number", OutboundNumber: "+15550001111", InboundAddresses: []string{"10.0.0.0/8"}},
+			{SipTrunkId: "ST_default", InboundAddresses: []string{"192.168.1.10"}, InboundNumbersRegex: []string{`^\+1`}},
+		}, nil)
+		cases := []struct {
+			name    string
+			req     *livekit.CreateSIPTrunkRequest
+			problem string
+			warning bool
+		}{
+			{name: "overlapping addresses", req: &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550001111", InboundAddresses: []string{"10.1.0.0/16", "172.16.0.1"}}, problem: "outbound_number"},
+			{name: "any address", req: &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550001111"}, problem: "outbound_number"},
+			{name: "other addresses", req: &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550001111", InboundAddresses: []string{"172.16.0.0/12"}}},
+			{name: "other number", req: &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550002222", InboundAddresses: []string{"10.0.0.1"}}},
+			// a trunk for the called number is preferred to a default one
+			{name: "number and default", req: &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550002222", InboundAddresses: []string{"192.168.1.10"}}},
+			{name: "two defaults", req: &livekit.CreateSIPTrunkRequest{InboundAddresses: []string{"192.168.1.0/24"}}, problem: "outbound_number"},
+			{name: "shared pattern", req: &livekit.CreateSIPTrunkRequest{InboundNumbersRegex: []string{`^\+44`, `^\+1`}}, problem: "outbound_number"},
+			{name: "other patterns", req: &livekit.CreateSIPTrunkRequest{InboundNumbersRegex: []string{`^\+1555`}}, problem: "inbound_numbers_regex", warning: true},
+		}
+		for _, c := range cases {
+			problems, err := svc.ValidateSIPTrunk(sipAdminContext(), c.req)
+			require.NoError(t, err, c.name)
+			if c.problem == "" {
+				require.Empty(t, problems, c.name)
+				continue
+			}
+			require.Len(t, problems, 1, c.name)
+			require.Equal(t, c.problem, problems[0].Field, c.name)
+			require.Equal(t, c.warning, problems[0].Warning, c.name)
+		}
+
+		// warnings do not fail a create
+		_, err := svc.CreateSIPTrunk(sipAdminContext(), &livekit.CreateSIPTrunkRequest{InboundNumbersRegex: []string{`^\+1555`}})
+		require.NoError(t, err)
+		_, err = svc.CreateSIPTrunk(sipAdminContext(), &livekit.CreateSIPTrunkRequest{InboundAddresses: []string{"192.168.1.0/24"}})
+		require.Error(t, err)
+		require.Equal(t, 1, store.StoreSIPTrunkCallCount())
+	})
+
+	t.Run("create", func(t *testing.T) {
+		_, err := svc.CreateSIPTrunk(sipAdminContext(), &livekit.CreateSIPTrunkRequest{OutboundNumber: "call me"})
+		var perr psrpc.Error
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, psrpc.InvalidArgument, perr.Code())
+		require.Equal(t, 0, store.StoreSIPTrunkCallCount())
+
+		_, err = svc.CreateSIPTrunk(sipAdminContext(), &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550002222"})
+		require.NoError(t, err)
+		require.Equal(t, 1, store.StoreSIPTrunkCallCount())
+	})
+}
+
+func TestCreateSIPParticipantUnknownTrunk(t *testing.T) {
+	svc, store := newTestSIPService(config.SIPConfig{MaxParallelDials: 1})
+	store.LoadSIPTrunkReturns(nil, service.ErrSIPTrunkNotFound)
+	_, err := svc.CreateSIPParticipant(sipAdminContext(), &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_unknown"})
+	require.ErrorIs(t, err, service.ErrSIPTrunkNotFound)
+	require.Equal(t, 0, store.StoreSIPParticipantCallCount())
+}
+
+func TestCreateSIPParticipantCancel(t *testing.T) {
+	t.Run("cancelled while storing", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		ctx, cancel := context.WithCancel(sipAdminContext())
+		store.StoreSIPParticipantStub = func(ctx context.Context, info *livekit.SIPParticipantInfo) error {
+			cancel()
+			return nil
+		}
+		var deleteErr error
+		store.DeleteSIPParticipantStub = func(ctx context.Context, info *livekit.SIPParticipantInfo) error {
+			// cleanup must not use the cancelled request context
+			deleteErr = ctx.Err()
+			return nil
+		}
+
+		_, err := svc.CreateSIPParticipant(ctx, &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_aaa"})
+		require.ErrorIs(t, err, context.Canceled)
+
+		// no orphaned participant remains
+		require.Equal(t, 1, store.DeleteSIPParticipantCallCount())
+		_, stored := store.StoreSIPParticipantArgsForCall(0)
+		_, deleted := store.DeleteSIPParticipantArgsForCall(0)
+		require.Equal(t, stored.SipParticipantId, deleted.SipParticipantId)
+		require.NoError(t, deleteErr)
+	})
+
+	for _, c := range []struct {
+		name string
+		conf config.SIPConfig
+		exp  error
+	}{
+		{name: "cancelled while queued", conf: config.SIPConfig{MaxParallelDials: 1}, exp: context.Canceled},
+		{name: "timed out while queued", conf: config.SIPConfig{MaxParallelDials: 1, DialTimeout: 20 * time.Millisecond}, exp: context.DeadlineExceeded},
+	} {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			svc, store := newTestSIPService(c.conf)
+			unblock := make(chan struct{})
+			store.StoreSIPParticipantStub = func(ctx context.Context, info *livekit.SIPParticipantInfo) error {
+				<-unblock
+				return nil
+			}
+
+			// the first dial holds the only slot on the trunk
+			first := make(chan error, 1)
+			go func() {
+				_, err := svc.CreateSIPParticipant(sipAdminContext(), &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_aaa"})
+				first <- err
+			}()
+			require.Eventually(t, func() bool { return store.StoreSIPParticipantCallCount() == 1 }, time.Second, 5*time.Millisecond)
+
+			ctx, cancel := context.WithCancel(sipAdminContext())
+			defer cancel()
+			if c.exp == context.Canceled {
+				time.AfterFunc(20*time.Millisecond, cancel)
+			}
+			_, err := svc.CreateSIPParticipant(ctx, &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_aaa"})
+			require.ErrorIs(t, err, c.exp)
+			require.Equal(t, 1, store.StoreSIPParticipantCallCount())
+			require.Equal(t, 0, store.DeleteSIPParticipantCallCount())
+
+			close(unblock)
+			err = <-first
+			if c.conf.DialTimeout > 0 {
+				// the first dial outlived the timeout as well, and is cleaned up
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+				require.Equal(t, 1, store.DeleteSIPParticipantCallCount())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// sipMemoryStore keeps trunks in memory, for tests going through the HTTP API.
+type sipMemoryStore struct {
+	service.SIPStore
+	mu     sync.Mutex
+	trunks map[string]*livekit.SIPTrunkInfo
+}
+
+func (s *sipMemoryStore) StoreSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trunks[info.SipTrunkId] = info
+	return nil
+}
+
+func (s *sipMemoryStore) LoadSIPTrunk(ctx context.Context, id string) (*livekit.SIPTrunkInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.trunks[id]; ok {
+		return t, nil
+	}
+	return nil, service.ErrSIPTrunkNotFound
+}
+
+func (s *sipMemoryStore) ListSIPTrunk(ctx context.Context) ([]*livekit.SIPTrunkInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*livekit.SIPTrunkInfo
+	for _, t := range s.trunks {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (s *sipMemoryStore) DeleteSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.trunks, info.SipTrunkId)
+	return nil
+}
+
+func (s *sipMemoryStore) RunSIPTxn(ctx context.Context, fn func(tx service.SIPTxn) error) error {
+	return fn(s)
+}
+
+func TestSIPTwirp(t *testing.T) {
+	const (
+		apiKey    = "APIabcdefg"
+		apiSecret = "somesecretencodedinbase62"
+	)
+	provider := &authfakes.FakeKeyProvider{}
+	provider.GetSecretReturns(apiSecret)
+
+	conf := config.SIPConfig{}
+	store := &sipMemoryStore{trunks: make(map[string]*livekit.SIPTrunkInfo)}
+	svc := service.NewSIPService(&conf, "node", nil, nil, store, nil, nil)
+
+	n := negroni.New(service.NewAPIKeyAuthMiddleware(provider))
+	n.UseHandler(livekit.NewSIPServer(svc, service.TwirpLogger(logger.GetLogger())))
+	srv := httptest.NewServer(n)
+	defer srv.Close()
+
+	withToken := func(grant *auth.VideoGrant) context.Context {
+		token, err := auth.NewAccessToken(apiKey, apiSecret).AddGrant(grant).ToJWT()
+		require.NoError(t, err)
+		h := http.Header{}
+		service.SetAuthorizationToken(&http.Request{Header: h}, token)
+		ctx, err := twirp.WithHTTPRequestHeaders(context.Background(), h)
+		require.NoError(t, err)
+		return ctx
+	}
+	requireCode := func(t *testing.T, err error, code twirp.ErrorCode) {
+		var terr twirp.Error
+		require.ErrorAs(t, err, &terr)
+		require.Equal(t, code, terr.Code())
+	}
+
+	ctx := withToken(&auth.VideoGrant{RoomCreate: true})
+	for name, client := range map[string]livekit.SIP{
+		"protobuf": livekit.NewSIPProtobufClient(srv.URL, &http.Client{}),
+		"json":     livekit.NewSIPJSONClient(srv.URL, &http.Client{}),
+	} {
+		client := client
+		t.Run(name, func(t *testing.T) {
+			trunk, err := client.CreateSIPTrunk(ctx, &livekit.CreateSIPTrunkRequest{
+				OutboundAddress: "sip.example.com",
+				OutboundNumber:  "+15550001111",
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, trunk.SipTrunkId)
+
+			list, err := client.ListSIPTrunk(ctx, &livekit.ListSIPTrunkRequest{})
+			require.NoError(t, err)
+			require.Len(t, list.Items, 1)
+			require.Equal(t, trunk.SipTrunkId, list.Items[0].SipTrunkId)
+
+			deleted, err := client.DeleteSIPTrunk(ctx, &livekit.DeleteSIPTrunkRequest{SipTrunkId: trunk.SipTrunkId})
+			require.NoError(t, err)
+			require.Equal(t, trunk.SipTrunkId, deleted.SipTrunkId)
+
+			list, err = client.ListSIPTrunk(ctx, &livekit.ListSIPTrunkRequest{})
+			require.NoError(t, err)
+			require.Empty(t, list.Items)
+
+			// error codes are carried over
+			_, err = client.DeleteSIPTrunk(ctx, &livekit.DeleteSIPTrunkRequest{SipTrunkId: trunk.SipTrunkId})
+			requireCode(t, err, twirp.NotFound)
+			_, err = client.CreateSIPTrunk(ctx, &livekit.CreateSIPTrunkRequest{OutboundNumber: "call me"})
+			requireCode(t, err, twirp.InvalidArgument)
+		})
+	}
+
+	t.Run("auth", func(t *testing.T) {
+		client := livekit.NewSIPProtobufClient(srv.URL, &http.Client{})
+		_, err := client.ListSIPTrunk(context.Background(), &livekit.ListSIPTrunkRequest{})
+		requireCode(t, err, twirp.Unauthenticated)
+
+		_, err = client.ListSIPTrunk(withToken(&auth.VideoGrant{RoomJoin: true, Room: "room"}), &livekit.ListSIPTrunkRequest{})
+		requireCode(t, err, twirp.Unauthenticated)
+	})
+}
+
+func TestSIPQuotas(t *testing.T) {
+	conf := config.SIPConfig{MaxTrunks: 1, MaxDispatchRules: 1, MaxParticipants: 1}
+	requireExhausted := func(t *testing.T, err error) {
+		var perr psrpc.Error
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, psrpc.ResourceExhausted, perr.Code())
+	}
+
+	t.Run("trunks", func(t *testing.T) {
+		svc, store := newTestSIPService(conf)
+		_, err := svc.CreateSIPTrunk(sipAdminContext(), &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550001111"})
+		require.NoError(t, err)
+
+		store.ListSIPTrunkReturns([]*livekit.SIPTrunkInfo{{SipTrunkId: "ST_aaa", OutboundNumber: "+15550001111"}}, nil)
+		_, err = svc.CreateSIPTrunk(sipAdminContext(), &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550002222"})
+		require.ErrorIs(t, err, service.ErrSIPTrunkQuota)
+		requireExhausted(t, err)
+		require.Equal(t, 1, store.StoreSIPTrunkCallCount())
+	})
+
+	t.Run("dispatch rules", func(t *testing.T) {
+		svc, store := newTestSIPService(conf)
+		store.ListSIPDispatchRuleReturns([]*livekit.SIPDispatchRuleInfo{{SipDispatchRuleId: "SDR_aaa"}}, nil)
+		_, err := svc.CreateSIPDispatchRule(sipAdminContext(), &livekit.CreateSIPDispatchRuleRequest{
+			Rule: &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleIndividual{
+				DispatchRuleIndividual: &livekit.SIPDispatchRuleIndividual{RoomPrefix: "call-"},
+			}},
+		})
+		require.ErrorIs(t, err, service.ErrSIPDispatchRuleQuota)
+		requireExhausted(t, err)
+		require.Equal(t, 0, store.StoreSIPDispatchRuleCallCount())
+	})
+
+	t.Run("participants", func(t *testing.T) {
+		svc, store := newTestSIPService(conf)
+		store.ListSIPParticipantReturns([]*livekit.SIPParticipantInfo{{SipParticipantId: "SP_aaa"}}, nil)
+		_, err := svc.CreateSIPParticipant(sipAdminContext(), &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_aaa"})
+		require.ErrorIs(t, err, service.ErrSIPParticipantQuota)
+		requireExhausted(t, err)
+		require.Equal(t, 0, store.StoreSIPParticipantCallCount())
+		// checked in a transaction, so concurrent dials see each other
+		require.Equal(t, 1, store.RunSIPTxnCallCount())
+	})
+
+	t.Run("import", func(t *testing.T) {
+		svc, store := newTestSIPService(conf)
+		res, err := svc.ImportSIPConfig(sipAdminContext(), &service.SIPConfigDocument{
+			Trunks: []*livekit.SIPTrunkInfo{{SipTrunkId: "ST_aaa", OutboundNumber: "+15550001111"}, {SipTrunkId: "ST_bbb", OutboundNumber: "+15550002222"}},
+		}, service.SIPImportOptions{DryRun: true})
+		require.NoError(t, err)
+		require.Equal(t, service.SIPImportCreated, res.Trunks[0].Action)
+		require.Equal(t, service.SIPImportFailed, res.Trunks[1].Action)
+		require.ErrorIs(t, res.Trunks[1].Error, service.ErrSIPTrunkQuota)
+		require.Equal(t, 0, store.StoreSIPTrunkCallCount())
+	})
+}
+
+func TestSIPParticipantEvents(t *testing.T) {
+	svc, store := newTestSIPService(config.SIPConfig{ParticipantEventLimit: 10, ParticipantEventTTL: time.Hour})
+	var events []*service.SIPParticipantEvent
+	store.AppendSIPParticipantEventStub = func(ctx context.Context, id string, event *service.SIPParticipantEvent, maxEvents int, ttl time.Duration) error {
+		require.Equal(t, 10, maxEvents)
+		require.Equal(t, time.Hour, ttl)
+		events = append(events, event)
+		return nil
+	}
+	store.ListSIPParticipantEventsStub = func(ctx context.Context, id string) ([]*service.SIPParticipantEvent, error) {
+		return events, nil
+	}
+
+	info, err := svc.CreateSIPParticipant(sipAdminContext(), &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_aaa", RoomName: "support"})
+	require.NoError(t, err)
+	store.LoadSIPParticipantReturns(info, nil)
+	_, err = svc.DeleteSIPParticipant(sipAdminContext(), &livekit.DeleteSIPParticipantRequest{SipParticipantId: info.SipParticipantId})
+	require.NoError(t, err)
+
+	_, err = svc.GetSIPParticipantEvents(context.Background(), info.SipParticipantId)
+	require.Error(t, err)
+
+	got, err := svc.GetSIPParticipantEvents(sipAdminContext(), info.SipParticipantId)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, service.SIPParticipantEventCreated, got[0].Type)
+	require.Contains(t, got[0].Detail, "support")
+	require.Equal(t, service.SIPParticipantEventDeleted, got[1].Type)
+	require.False(t, got[1].Time.Before(got[0].Time))
+	for i := 0; i < store.AppendSIPParticipantEventCallCount(); i++ {
+		_, id, _, _, _ := store.AppendSIPParticipantEventArgsForCall(i)
+		require.Equal(t, info.SipParticipantId, id)
+	}
+
+	// a failing event store does not fail the call
+	svc, store = newTestSIPService(config.SIPConfig{ParticipantEventLimit: 10})
+	store.AppendSIPParticipantEventReturns(errors.New("store down"))
+	_, err = svc.CreateSIPParticipant(sipAdminContext(), &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_aaa"})
+	require.NoError(t, err)
+	require.Equal(t, 1, store.AppendSIPParticipantEventCallCount())
+
+	// recording is disabled
+	svc, store = newTestSIPService(config.SIPConfig{})
+	_, err = svc.CreateSIPParticipant(sipAdminContext(), &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_aaa"})
+	require.NoError(t, err)
+	require.Equal(t, 0, store.AppendSIPParticipantEventCallCount())
+}
+
+func TestSIPListBudget(t *testing.T) {
+	svc, store := newTestSIPService(config.SIPConfig{MaxStoreListOps: 1})
+	unblock := make(chan struct{})
+	store.ListSIPTrunkStub = func(ctx context.Context) ([]*livekit.SIPTrunkInfo, error) {
+		<-unblock
+		return nil, nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.ListSIPTrunk(sipAdminContext(), &livekit.ListSIPTrunkRequest{})
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return store.ListSIPTrunkCallCount() == 1 }, time.Second, 5*time.Millisecond)
+
+	// the budget is shared by all list requests
+	_, err := svc.ListSIPParticipant(sipAdminContext(), &livekit.ListSIPParticipantRequest{})
+	require.ErrorIs(t, err, service.ErrSIPStoreBusy)
+
+	// call setup is not affected by listing
+	_, err = svc.CreateSIPParticipant(sipAdminContext(), &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_aaa"})
+	require.NoError(t, err)
+
+	close(unblock)
+	require.NoError(t, <-done)
+	_, err = svc.ListSIPParticipant(sipAdminContext(), &livekit.ListSIPParticipantRequest{})
+	require.NoError(t, err)
+}
+
+func TestSIPListOrder(t *testing.T) {
+	svc, store := newTestSIPService(config.SIPConfig{})
+	store.ListSIPTrunkReturns([]*livekit.SIPTrunkInfo{{SipTrunkId: "ST_c"}, {SipTrunkId: "ST_a"}, {SipTrunkId: "ST_b"}}, nil)
+	store.ListSIPDispatchRuleReturns([]*livekit.SIPDispatchRuleInfo{{SipDispatchRuleId: "SDR_b"}, {SipDispatchRuleId: "SDR_a"}}, nil)
+	store.ListSIPParticipantReturns([]*livekit.SIPParticipantInfo{{SipParticipantId: "SP_b"}, {SipParticipantId: "SP_a"}}, nil)
+
+	trunks, err := svc.ListSIPTrunk(sipAdminContext(), &livekit.ListSIPTrunkRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"ST_a", "ST_b", "ST_c"}, []string{trunks.Items[0].SipTrunkId, trunks.Items[1].SipTrunkId, trunks.Items[2].SipTrunkId})
+
+	rules, err := svc.ListSIPDispatchRule(sipAdminContext(), &livekit.ListSIPDispatchRuleRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "SDR_a", rules.Items[0].SipDispatchRuleId)
+
+	participants, err := svc.ListSIPParticipant(sipAdminContext(), &livekit.ListSIPParticipantRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "SP_a", participants.Items[0].SipParticipantId)
+
+	doc, err := svc.ExportSIPConfig(sipAdminContext(), false)
+	require.NoError(t, err)
+	require.Equal(t, "ST_a", doc.Trunks[0].SipTrunkId)
+	require.Equal(t, "SDR_a", doc.DispatchRules[0].SipDispatchRuleId)
+}
+
+func TestBatchGetSIPParticipant(t *testing.T) {
+	svc, store := newTestSIPService(config.SIPConfig{})
+	store.LoadSIPParticipantsStub = func(ctx context.Context, ids []string) ([]*livekit.SIPParticipantInfo, error) {
+		var out []*livekit.SIPParticipantInfo
+		for _, id := range ids {
+			if id != "SP_missing" {
+				out = append(out, &livekit.SIPParticipantInfo{SipParticipantId: id})
+			}
+		}
+		return out, nil
+	}
+
+	res, err := svc.BatchGetSIPParticipant(sipAdminContext(), []string{"SP_b", "SP_missing", "SP_a", "SP_b"})
+	require.NoError(t, err)
+	require.Len(t, res.Items, 2)
+	require.Equal(t, "SP_b", res.Items[0].SipParticipantId)
+	require.Equal(t, "SP_a", res.Items[1].SipParticipantId)
+	require.Equal(t, []string{"SP_missing"}, res.Missing)
+
+	// duplicates are only loaded once, in a single store request
+	require.Equal(t, 1, store.LoadSIPParticipantsCallCount())
+	_, ids := store.LoadSIPParticipantsArgsForCall(0)
+	require.Equal(t, []string{"SP_b", "SP_missing", "SP_a"}, ids)
+
+	_, err = svc.BatchGetSIPParticipant(sipAdminContext(), make([]string, 101))
+	var perr psrpc.Error
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, psrpc.InvalidArgument, perr.Code())
+
+	_, err = svc.BatchGetSIPParticipant(context.Background(), []string{"SP_a"})
+	require.Error(t, err)
+}
+
+func TestSIPValidateIDs(t *testing.T) {
+	const (
+		trunkID = "ST_aaa"
+		ruleID  = "SDR_aaa"
+		partID  = "SP_aaa"
+	)
+	svc, store := newTestSIPService(config.SIPConfig{})
+	ctx := sipAdminContext()
+	calls := map[string]func(id string) error{
+		"DeleteSIPTrunk": func(id string) error {
+			_, err := svc.DeleteSIPTrunk(ctx, &livekit.DeleteSIPTrunkRequest{SipTrunkId: id})
+			return err
+		},
+		"CreateSIPDispatchRule": func(id string) error {
+			_, err := svc.CreateSIPDispatchRule(ctx, &livekit.CreateSIPDispatchRuleRequest{
+				TrunkIds: []string{id},
+				Rule: &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleDirect{
+					DispatchRuleDirect: &livekit.SIPDispatchRuleDirect{RoomName: "support"},
+				}},
+			})
+			return err
+		},
+		"CreateSIPParticipant": func(id string) error {
+			_, err := svc.CreateSIPParticipant(ctx, &livekit.CreateSIPParticipantRequest{SipTrunkId: id})
+			return err
+		},
+		"DeleteSIPDispatchRule": func(id string) error {
+			_, err := svc.DeleteSIPDispatchRule(ctx, &livekit.DeleteSIPDispatchRuleRequest{SipDispatchRuleId: id})
+			return err
+		},
+		"DeleteSIPParticipant": func(id string) error {
+			_, err := svc.DeleteSIPParticipant(ctx, &livekit.DeleteSIPParticipantRequest{SipParticipantId: id})
+			return err
+		},
+		"SendSIPParticipantDTMF": func(id string) error {
+			_, err := svc.SendSIPParticipantDTMF(ctx, &livekit.SendSIPParticipantDTMFRequest{SipParticipantId: id, Digits: "1"})
+			return err
+		},
+		"GetSIPParticipantEvents": func(id string) error {
+			_, err := svc.GetSIPParticipantEvents(ctx, id)
+			return err
+		},
+		"BatchGetSIPParticipant": func(id string) error {
+			_, err := svc.BatchGetSIPParticipant(ctx, []string{id})
+			return err
+		},
+	}
+	expected := map[string]string{
+		"DeleteSIPTrunk":          trunkID,
+		"CreateSIPDispatchRule":   trunkID,
+		"CreateSIPParticipant":    trunkID,
+		"DeleteSIPDispatchRule":   ruleID,
+		"DeleteSIPParticipant":    partID,
+		"SendSIPParticipantDTMF":  partID,
+		"GetSIPParticipantEvents": partID,
+		"BatchGetSIPParticipant":  partID,
+	}
+	store.LoadSIPTrunkReturns(&livekit.SIPTrunkInfo{SipTrunkId: trunkID}, nil)
+	store.LoadSIPDispatchRuleReturns(&livekit.SIPDispatchRuleInfo{SipDispatchRuleId: ruleID}, nil)
+	store.LoadSIPParticipantReturns(&livekit.SIPParticipantInfo{SipParticipantId: partID}, nil)
+
+	for name, call := range calls {
+		for _, id := range []string{trunkID, ruleID, partID, "RM_aaa", "ST_", "ST_a:b", "random"} {
+			err := call(id)
+			if id == expected[name] {
+				// DTMF sending is not implemented, any other error is fine
+				var perr psrpc.Error
+				if errors.As(err, &perr) {
+					require.NotEqual(t, psrpc.InvalidArgument, perr.Code(), "%s(%s)", name, id)
+				}
+				continue
+			}
+			var perr psrpc.Error
+			require.ErrorAs(t, err, &perr, "%s(%s)", name, id)
+			require.Equal(t, psrpc.InvalidArgument, perr.Code(), "%s(%s)", name, id)
+		}
+	}
+	// nothing with an invalid ID reached the store
+	for i := 0; i < store.LoadSIPTrunkCallCount(); i++ {
+		_, id := store.LoadSIPTrunkArgsForCall(i)
+		require.Equal(t, trunkID, id)
+	}
+	for i := 0; i < store.LoadSIPParticipantCallCount(); i++ {
+		_, id := store.LoadSIPParticipantArgsForCall(i)
+		require.Equal(t, partID, id)
+	}
+
+	t.Run("import", func(t *testing.T) {
+		res, err := svc.ImportSIPConfig(ctx, &service.SIPConfigDocument{
+			Trunks: []*livekit.SIPTrunkInfo{{SipTrunkId: ruleID}},
+			DispatchRules: []*livekit.SIPDispatchRuleInfo{{
+				SipDispatchRuleId: trunkID,
+				Rule: &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleDirect{
+					DispatchRuleDirect: &livekit.SIPDispatchRuleDirect{RoomName: "support"},
+				}},
+			}},
+		}, service.SIPImportOptions{PreserveIDs: true, DryRun: true})
+		require.NoError(t, err)
+		require.Equal(t, service.SIPImportFailed, res.Trunks[0].Action)
+		require.Equal(t, service.SIPImportFailed, res.DispatchRules[0].Action)
+	})
+}
+
+func TestDeleteSIPTrunkPolicy(t *testing.T) {
+	newRules := func() []*livekit.SIPDispatchRuleInfo {
+		return []*livekit.SIPDispatchRuleInfo{
+			{SipDispatchRuleId: "SDR_only", TrunkIds: []string{"ST_aaa"}},
+			{SipDispatchRuleId: "SDR_shared", TrunkIds: []string{"ST_bbb", "ST_aaa"}},
+			{SipDispatchRuleId: "SDR_other", TrunkIds: []string{"ST_bbb"}},
+			{SipDispatchRuleId: "SDR_any"},
+		}
+	}
+	newService := func(policy config.SIPTrunkDeletePolicy) (*service.SIPService, *servicefakes.FakeSIPStore) {
+		svc, store := newTestSIPService(config.SIPConfig{TrunkDeletePolicy: policy})
+		store.LoadSIPTrunkReturns(&livekit.SIPTrunkInfo{SipTrunkId: "ST_aaa"}, nil)
+		store.ListSIPDispatchRuleReturns(newRules(), nil)
+		return svc, store
+	}
+	req := &livekit.DeleteSIPTrunkRequest{SipTrunkId: "ST_aaa"}
+
+	t.Run("keep rules", func(t *testing.T) {
+		svc, store := newService("")
+		_, err := svc.DeleteSIPTrunk(sipAdminContext(), req)
+		require.NoError(t, err)
+		require.Equal(t, 1, store.DeleteSIPTrunkCallCount())
+		require.Equal(t, 0, store.ListSIPDispatchRuleCallCount())
+	})
+
+	t.Run("block", func(t *testing.T) {
+		svc, store := newService(config.SIPTrunkDeleteBlock)
+		_, err := svc.DeleteSIPTrunk(sipAdminContext(), req)
+		require.ErrorIs(t, err, service.ErrSIPTrunkInUse)
+		require.Contains(t, err.Error(), "SDR_only, SDR_shared")
+		require.Equal(t, 0, store.DeleteSIPTrunkCallCount())
+
+		store.ListSIPDispatchRuleReturns(nil, nil)
+		_, err = svc.DeleteSIPTrunk(sipAdminContext(), req)
+		require.NoError(t, err)
+		require.Equal(t, 1, store.DeleteSIPTrunkCallCount())
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		svc, store := newService(config.SIPTrunkDeleteCascade)
+		_, err := svc.DeleteSIPTrunk(sipAdminContext(), req)
+		require.NoError(t, err)
+		require.Equal(t, 1, store.DeleteSIPTrunkCallCount())
+
+		require.Equal(t, 1, store.DeleteSIPDispatchRuleCallCount())
+		_, deleted := store.DeleteSIPDispatchRuleArgsForCall(0)
+		require.Equal(t, "SDR_only", deleted.SipDispatchRuleId)
+
+		require.Equal(t, 1, store.StoreSIPDispatchRuleCallCount())
+		_, updated := store.StoreSIPDispatchRuleArgsForCall(0)
+		require.Equal(t, "SDR_shared", updated.SipDispatchRuleId)
+		require.Equal(t, []string{"ST_bbb"}, updated.TrunkIds)
+	})
+}
+
+// sipRoomList reports a fixed set of rooms as existing.
+type sipRoomList struct {
+	livekit.RoomService
+	rooms []string
+}
+
+func (s *sipRoomList) ListRooms(ctx context.Context, req *livekit.ListRoomsRequest) (*livekit.ListRoomsResponse, error) {
+	res := &livekit.ListRoomsResponse{}
+	for _, name := range req.Names {
+		for _, r := range s.rooms {
+			if r == name {
+				res.Rooms = append(res.Rooms, &livekit.Room{Name: name})
+			}
+		}
+	}
+	return res, nil
+}
+
+func TestReportSIPDispatchRules(t *testing.T) {
+	direct := func(room string) *livekit.SIPDispatchRule {
+		return &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleDirect{
+			DispatchRuleDirect: &livekit.SIPDispatchRuleDirect{RoomName: room},
+		}}
+	}
+	now := time.Now()
+	store := &servicefakes.FakeSIPStore{}
+	store.ListSIPDispatchRuleReturns([]*livekit.SIPDispatchRuleInfo{
+		{SipDispatchRuleId: "SDR_active", Rule: direct("lobby")},
+		{SipDispatchRuleId: "SDR_idle", Rule: direct("lobby")},
+		{SipDispatchRuleId: "SDR_gone", Rule: direct("old")},
+		{SipDispatchRuleId: "SDR_new_gone", Rule: direct("older")},
+		{SipDispatchRuleId: "SDR_new", Rule: direct("lobby")},
+		{SipDispatchRuleId: "SDR_unused", Rule: direct("lobby")},
+		{SipDispatchRuleId: "SDR_individual", Rule: &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleIndividual{
+			DispatchRuleIndividual: &livekit.SIPDispatchRuleIndividual{RoomPrefix: "call-"},
+		}}},
+	}, nil)
+	store.LoadSIPDispatchRuleActivityReturns(map[string]*service.SIPDispatchRuleActivity{
+		"SDR_active":     {LastMatched: now.Add(-time.Hour)},
+		"SDR_idle":       {LastMatched: now.Add(-30 * 24 * time.Hour)},
+		"SDR_gone":       {LastMatched: now.Add(-time.Hour), RoomMissingSince: now.Add(-10 * 24 * time.Hour)},
+		"SDR_new_gone":   {LastMatched: now.Add(-time.Hour)},
+		"SDR_individual": {LastMatched: now.Add(-time.Hour)},
+		"SDR_unused":     {FirstSeen: now.Add(-30 * 24 * time.Hour)},
+	}, nil)
+	svc := service.NewSIPService(&config.SIPConfig{}, "node", nil, nil, store, &sipRoomList{rooms: []string{"lobby"}}, nil)
+
+	res, err := svc.ReportSIPDispatchRules(sipAdminContext(), service.SIPDispatchRuleReportOptions{
+		IdleFor:        7 * 24 * time.Hour,
+		RoomMissingFor: 7 * 24 * time.Hour,
+	})
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+	require.Equal(t, "SDR_gone", res[0].SipDispatchRuleId)
+	require.True(t, res[0].RoomMissing)
+	require.False(t, res[0].Idle)
+	require.Equal(t, "old", res[0].RoomName)
+	require.Equal(t, "SDR_idle", res[1].SipDispatchRuleId)
+	require.True(t, res[1].Idle)
+	require.False(t, res[1].RoomMissing)
+	// never matched, but listed by a report long ago
+	require.Equal(t, "SDR_unused", res[2].SipDispatchRuleId)
+	require.True(t, res[2].Idle)
+
+	// a newly created rule is not idle, and is recorded as seen so that a later report can tell its age
+	require.Equal(t, 1, store.MarkSIPDispatchRulesSeenCallCount())
+	_, ids, at := store.MarkSIPDispatchRulesSeenArgsForCall(0)
+	require.Equal(t, []string{"SDR_new"}, ids)
+	require.False(t, at.IsZero())
+
+	// the newly missing room is recorded, so that a later report can tell how long it has been gone
+	require.Equal(t, 1, store.SetSIPDispatchRuleRoomMissingCallCount())
+	_, id, since := store.SetSIPDispatchRuleRoomMissingArgsForCall(0)
+	require.Equal(t, "SDR_new_gone", id)
+	require.False(t, since.IsZero())
+}
+
+func TestUpdateSIPTrunkInboundNumbersRegex(t *testing.T) {
+	newService := func() (*service.SIPService, *servicefakes.FakeSIPStore) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.LoadSIPTrunkStub = func(ctx context.Context, id string) (*livekit.SIPTrunkInfo, error) {
+			return &livekit.SIPTrunkInfo{SipTrunkId: id, OutboundNumber: "+15550001111", InboundNumbersRegex: []string{"^\\+1"}}, nil
+		}
+		return svc, store
+	}
+
+	t.Run("one bad pattern keeps the old set", func(t *testing.T) {
+		svc, store := newService()
+		_, err := svc.UpdateSIPTrunkInboundNumbersRegex(sipAdminContext(), "ST_aaa", []string{"^\\+44", "[unclosed"})
+		var perr psrpc.Error
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, psrpc.InvalidArgument, perr.Code())
+		require.Contains(t, err.Error(), "[unclosed")
+		require.Equal(t, 0, store.StoreSIPTrunkCallCount())
+	})
+
+	t.Run("update", func(t *testing.T) {
+		svc, store := newService()
+		info, err := svc.UpdateSIPTrunkInboundNumbersRegex(sipAdminContext(), "ST_aaa", []string{"^\\+44", "^\\+33"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"^\\+44", "^\\+33"}, info.InboundNumbersRegex)
+		require.Equal(t, 1, store.StoreSIPTrunkCallCount())
+		_, stored := store.StoreSIPTrunkArgsForCall(0)
+		require.Equal(t, "+15550001111", stored.OutboundNumber)
+		require.Equal(t, []string{"^\\+44", "^\\+33"}, stored.InboundNumbersRegex)
+	})
+}
+
+func TestCreateSIPBatch(t *testing.T) {
+	reqs := func() []*livekit.CreateSIPTrunkRequest {
+		return []*livekit.CreateSIPTrunkRequest{
+			{OutboundNumber: "+15550001111"},
+			{OutboundNumber: "+15550002222", InboundNumbersRegex: []string{"[unclosed"}},
+			{OutboundNumber: "+15550003333"},
+		}
+	}
+
+	t.Run("atomic validates all items", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		batch := reqs()
+		batch[2].OutboundNumber = batch[0].OutboundNumber
+		_, err := svc.CreateSIPTrunks(sipAdminContext(), batch, service.SIPBatchOptions{})
+		var perr psrpc.Error
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, psrpc.InvalidArgument, perr.Code())
+		var batchErr *service.SIPBatchError
+		require.ErrorAs(t, err, &batchErr)
+		require.Len(t, batchErr.Errors, 2)
+		require.Contains(t, batchErr.Errors[1].Error(), "inbound_numbers_regex")
+		require.Contains(t, batchErr.Errors[2].Error(), "outbound_number")
+		require.Equal(t, 0, store.StoreSIPTrunkCallCount())
+	})
+
+	t.Run("atomic", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		batch := reqs()
+		batch[1].InboundNumbersRegex = nil
+		infos, err := svc.CreateSIPTrunks(sipAdminContext(), batch, service.SIPBatchOptions{})
+		require.NoError(t, err)
+		require.Len(t, infos, 3)
+		require.Equal(t, 1, store.RunSIPTxnCallCount())
+		require.Equal(t, 3, store.StoreSIPTrunkCallCount())
+		for i, info := range infos {
+			require.Equal(t, batch[i].OutboundNumber, info.OutboundNumber)
+			_, stored := store.StoreSIPTrunkArgsForCall(i)
+			require.Equal(t, info.SipTrunkId, stored.SipTrunkId)
+		}
+	})
+
+	t.Run("atomic quota", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{MaxTrunks: 2})
+		batch := reqs()
+		batch[1].InboundNumbersRegex = nil
+		_, err := svc.CreateSIPTrunks(sipAdminContext(), batch, service.SIPBatchOptions{})
+		require.ErrorIs(t, err, service.ErrSIPTrunkQuota)
+		require.Equal(t, 0, store.StoreSIPTrunkCallCount())
+	})
+
+	t.Run("best effort", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		infos, err := svc.CreateSIPTrunks(sipAdminContext(), reqs(), service.SIPBatchOptions{BestEffort: true})
+		var batchErr *service.SIPBatchError
+		require.ErrorAs(t, err, &batchErr)
+		require.Len(t, batchErr.Errors, 1)
+		require.Error(t, batchErr.Errors[1])
+		require.Len(t, infos, 3)
+		require.NotNil(t, infos[0])
+		require.Nil(t, infos[1])
+		require.NotNil(t, infos[2])
+		require.Equal(t, 2, store.StoreSIPTrunkCallCount())
+	})
+
+	t.Run("dispatch rules", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		rule := &livekit.SIPDispatchRule{Rule: &livekit.SIPDispatchRule_DispatchRuleDirect{
+			DispatchRuleDirect: &livekit.SIPDispatchRuleDirect{RoomName: "lobby"},
+		}}
+		_, err := svc.CreateSIPDispatchRules(sipAdminContext(), []*livekit.CreateSIPDispatchRuleRequest{
+			{Rule: rule},
+			{Rule: rule, TrunkIds: []string{"bad"}},
+		}, service.SIPBatchOptions{})
+		var batchErr *service.SIPBatchError
+		require.ErrorAs(t, err, &batchErr)
+		require.Contains(t, batchErr.Errors, 1)
+		require.Equal(t, 0, store.StoreSIPDispatchRuleCallCount())
+
+		infos, err := svc.CreateSIPDispatchRules(sipAdminContext(), []*livekit.CreateSIPDispatchRuleRequest{
+			{Rule: rule},
+			{Rule: rule, TrunkIds: []string{"ST_aaa"}},
+		}, service.SIPBatchOptions{})
+		require.NoError(t, err)
+		require.Len(t, infos, 2)
+		require.Equal(t, []string{"ST_aaa"}, infos[1].TrunkIds)
+		require.Equal(t, 2, store.StoreSIPDispatchRuleCallCount())
+	})
+}
@@ -0,0 +1,203 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/psrpc"
+)
+
+type SIPBatchOptions struct {
+	// create each item on its own and report failures per item, instead of creating all items or none
+	BestEffort bool
+}
+
+// SIPBatchError holds the errors of a batch create by the index of the failing item.
+type SIPBatchError struct {
+	Errors map[int]error
+}
+
+func (e *SIPBatchError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	msgs := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		msgs = append(msgs, fmt.Sprintf("item %d: %v", i, e.Errors[i]))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *SIPBatchError) add(i int, err error) {
+	if e.Errors == nil {
+		e.Errors = make(map[int]error)
+	}
+	e.Errors[i] = err
+}
+
+func sipValidateBatchSize(n int) error {
+	if n == 0 {
+		return psrpc.NewErrorf(psrpc.InvalidArgument, "no items to create")
+	}
+	if n > sipMaxBatchCreate {
+		return psrpc.NewErrorf(psrpc.InvalidArgument, "too many items: %d, at most %d can be created at once", n, sipMaxBatchCreate)
+	}
+	return nil
+}
+
+// CreateSIPTrunks creates trunks in input order. By default every request is validated, including conflicts
+// between the requests themselves, and all trunks are stored in the same transaction. Validation errors are returned
+// together as an InvalidArgument error wrapping a *SIPBatchError. In best-effort mode the result has a nil entry
+// for every trunk that could not be created, and the error, if any, is a *SIPBatchError.
+func (s *SIPService) CreateSIPTrunks(ctx context.Context, reqs []*livekit.CreateSIPTrunkRequest, opts SIPBatchOptions) ([]*livekit.SIPTrunkInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+	if err := sipValidateBatchSize(len(reqs)); err != nil {
+		return nil, err
+	}
+
+	if opts.BestEffort {
+		infos := make([]*livekit.SIPTrunkInfo, len(reqs))
+		batchErr := &SIPBatchError{}
+		for i, req := range reqs {
+			info, err := s.CreateSIPTrunk(ctx, req)
+			if err != nil {
+				batchErr.add(i, err)
+				continue
+			}
+			infos[i] = info
+		}
+		if len(batchErr.Errors) != 0 {
+			return infos, batchErr
+		}
+		return infos, nil
+	}
+
+	infos := make([]*livekit.SIPTrunkInfo, 0, len(reqs))
+	for _, req := range reqs {
+		infos = append(infos, newSIPTrunkInfo(req))
+	}
+
+	err := s.store.RunSIPTxn(ctx, func(tx SIPTxn) error {
+		// the batch is stored as a unit, so finding one trunk means an earlier attempt was applied
+		if t, err := tx.LoadSIPTrunk(ctx, infos[0].SipTrunkId); err == nil && t != nil {
+			return nil
+		}
+		// validation runs in the transaction, so that conflicts with trunks created concurrently are found.
+		// Each item is checked against the stored trunks and the items before it.
+		trunks, err := tx.ListSIPTrunk(ctx)
+		if err != nil {
+			return err
+		}
+		batchErr := &SIPBatchError{}
+		for i, req := range reqs {
+			problems := sipValidateTrunkFields(req)
+			problems = append(problems, sipTrunkConflicts(req, "", trunks)...)
+			if err := sipTrunkProblemsError(problems); err != nil {
+				batchErr.add(i, err)
+			}
+			trunks = append(trunks, infos[i])
+		}
+		if len(batchErr.Errors) != 0 {
+			return psrpc.NewError(psrpc.InvalidArgument, batchErr)
+		}
+		if err := s.checkTrunkQuota(ctx, tx, len(infos)); err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if err := tx.StoreSIPTrunk(ctx, info); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// CreateSIPDispatchRules creates dispatch rules in input order, with the same modes and errors as CreateSIPTrunks.
+func (s *SIPService) CreateSIPDispatchRules(ctx context.Context, reqs []*livekit.CreateSIPDispatchRuleRequest, opts SIPBatchOptions) ([]*livekit.SIPDispatchRuleInfo, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+	if err := sipValidateBatchSize(len(reqs)); err != nil {
+		return nil, err
+	}
+
+	if opts.BestEffort {
+		infos := make([]*livekit.SIPDispatchRuleInfo, len(reqs))
+		batchErr := &SIPBatchError{}
+		for i, req := range reqs {
+			info, err := s.CreateSIPDispatchRule(ctx, req)
+			if err != nil {
+				batchErr.add(i, err)
+				continue
+			}
+			infos[i] = info
+		}
+		if len(batchErr.Errors) != 0 {
+			return infos, batchErr
+		}
+		return infos, nil
+	}
+
+	infos := make([]*livekit.SIPDispatchRuleInfo, 0, len(reqs))
+	batchErr := &SIPBatchError{}
+	for i, req := range reqs {
+		if err := sipValidateDispatchRuleRequest(req); err != nil {
+			batchErr.add(i, err)
+		}
+		infos = append(infos, newSIPDispatchRuleInfo(req))
+	}
+	if len(batchErr.Errors) != 0 {
+		return nil, psrpc.NewError(psrpc.InvalidArgument, batchErr)
+	}
+
+	err := s.store.RunSIPTxn(ctx, func(tx SIPTxn) error {
+		if r, err := tx.LoadSIPDispatchRule(ctx, infos[0].SipDispatchRuleId); err == nil && r != nil {
+			return nil
+		}
+		if err := s.checkDispatchRuleQuota(ctx, tx, len(infos)); err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if err := tx.StoreSIPDispatchRule(ctx, info); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+// sipDialQueue paces outbound dials per trunk, so that bulk campaigns do not trip carrier rate limits.
+// Each dial waits for a parallel slot, then for its turn according to the configured dial rate. The slot is held
+// while CreateSIPParticipant sets up the participant, not while the call rings on the SIP node.
+type sipDialQueue struct {
+	interval    time.Duration
+	maxParallel int
+	maxQueue    int
+
+	mu     sync.Mutex
+	trunks map[string]*sipTrunkDialQueue
+}
+
+type sipTrunkDialQueue struct {
+	// next is the earliest time the next dial is allowed to start
+	next    time.Time
+	waiting int
+	slots   chan struct{}
+}
+
+func newSIPDialQueue(conf *config.SIPConfig) *sipDialQueue {
+	q := &sipDialQueue{
+		maxParallel: conf.MaxParallelDials,
+		maxQueue:    conf.MaxDialQueueLength,
+		trunks:      make(map[string]*sipTrunkDialQueue),
+	}
+	if conf.MaxDialsPerSecond > 0 {
+		q.interval = time.Duration(float64(time.Second) / conf.MaxDialsPerSecond)
+	}
+	return q
+}
+
+func (q *sipDialQueue) enabled() bool {
+	return q.interval > 0 || q.maxParallel > 0
+}
+
+// Acquire blocks until a dial on the trunk is allowed to start. Cancelling the context removes the request
+// from the queue. The returned function must be called once the dial has completed.
+// The trunk ID is used as a map key and metric label, so callers must only pass IDs of stored trunks.
+func (q *sipDialQueue) Acquire(ctx context.Context, trunkID string) (func(), error) {
+	if !q.enabled() {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	q.pruneLocked(time.Now())
+	tq := q.trunks[trunkID]
+	if tq == nil {
+		tq = &sipTrunkDialQueue{}
+		if q.maxParallel > 0 {
+			tq.slots = make(chan struct{}, q.maxParallel)
+		}
+		q.trunks[trunkID] = tq
+	}
+	if q.maxQueue > 0 && tq.waiting >= q.maxQueue {
+		q.mu.Unlock()
+		prometheus.SIPDialRejected(trunkID, "queue_full")
+		return nil, ErrSIPDialQueueFull
+	}
+	tq.waiting++
+	q.mu.Unlock()
+
+	start := time.Now()
+	prometheus.SIPDialQueued(trunkID)
+	defer func() {
+		q.mu.Lock()
+		tq.waiting--
+		q.mu.Unlock()
+		prometheus.SIPDialDequeued(trunkID, time.Since(start))
+	}()
+
+	release := func() {}
+	if tq.slots != nil {
+		select {
+		case tq.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-tq.slots }
+	}
+
+	if q.interval > 0 {
+		q.mu.Lock()
+		at := time.Now()
+		if tq.next.After(at) {
+			at = tq.next
+		}
+		tq.next = at.Add(q.interval)
+		q.mu.Unlock()
+
+		if wait := time.Until(at); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				// give the turn back, unless a later dial has already been scheduled after it
+				q.mu.Lock()
+				if tq.next.Equal(at.Add(q.interval)) {
+					tq.next = at
+				}
+				q.mu.Unlock()
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return release, nil
+}
+
+// pruneLocked removes trunks without queued or running dials whose next turn has passed, so that the map only
+// holds trunks that are being dialed.
+func (q *sipDialQueue) pruneLocked(now time.Time) {
+	for id, tq := range q.trunks {
+		if tq.waiting == 0 && len(tq.slots) == 0 && !tq.next.After(now) {
+			delete(q.trunks, id)
+		}
+	}
+}
+
+// Waiting returns the number of dials queued for the trunk.
+func (q *sipDialQueue) Waiting(trunkID string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if tq := q.trunks[trunkID]; tq != nil {
+		return tq.waiting
+	}
+	return 0
+}
+
+// TotalWaiting returns the number of dials queued across all trunks.
+func (q *sipDialQueue) TotalWaiting() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	total := 0
+	for _, tq := range q.trunks {
+		total += tq.waiting
+	}
+	return total
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+func TestSIPDialQueue(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		q := newSIPDialQueue(&config.SIPConfig{})
+		for i := 0; i < 10; i++ {
+			_, err := q.Acquire(context.Background(), sipTrunkID1)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("parallel limit", func(t *testing.T) {
+		q := newSIPDialQueue(&config.SIPConfig{MaxParallelDials: 1})
+		release, err := q.Acquire(context.Background(), sipTrunkID1)
+		require.NoError(t, err)
+
+		// other trunks are not affected
+		release2, err := q.Acquire(context.Background(), sipTrunkID2)
+		require.NoError(t, err)
+		release2()
+
+		acquired := make(chan struct{})
+		go func() {
+			r, err := q.Acquire(context.Background(), sipTrunkID1)
+			if assert.NoError(t, err) {
+				r()
+			}
+			close(acquired)
+		}()
+		select {
+		case <-acquired:
+			t.Fatal("second dial should wait for the first one")
+		case <-time.After(50 * time.Millisecond):
+		}
+		require.Equal(t, 1, q.Waiting(sipTrunkID1))
+		release()
+		<-acquired
+		require.Equal(t, 0, q.Waiting(sipTrunkID1))
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		q := newSIPDialQueue(&config.SIPConfig{MaxParallelDials: 1, MaxDialQueueLength: 1})
+		release, err := q.Acquire(context.Background(), sipTrunkID1)
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			_, err := q.Acquire(ctx, sipTrunkID1)
+			done <- err
+		}()
+		require.Eventually(t, func() bool { return q.Waiting(sipTrunkID1) == 1 }, time.Second, 10*time.Millisecond)
+
+		_, err = q.Acquire(context.Background(), sipTrunkID1)
+		require.ErrorIs(t, err, ErrSIPDialQueueFull)
+
+		// cancelled requests leave the queue
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+		require.Equal(t, 0, q.Waiting(sipTrunkID1))
+	})
+
+	t.Run("pacing", func(t *testing.T) {
+		q := newSIPDialQueue(&config.SIPConfig{MaxDialsPerSecond: 20})
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			release, err := q.Acquire(context.Background(), sipTrunkID1)
+			require.NoError(t, err)
+			release()
+		}
+		require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("cancelled wait gives the turn back", func(t *testing.T) {
+		q := newSIPDialQueue(&config.SIPConfig{MaxDialsPerSecond: 1})
+		release, err := q.Acquire(context.Background(), sipTrunkID1)
+		require.NoError(t, err)
+		release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = q.Acquire(ctx, sipTrunkID1)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// the next dial takes the cancelled turn instead of waiting for the one after it
+		start := time.Now()
+		release, err = q.Acquire(context.Background(), sipTrunkID1)
+		require.NoError(t, err)
+		release()
+		require.Less(t, time.Since(start), 1500*time.Millisecond)
+	})
+
+	t.Run("idle trunks are pruned", func(t *testing.T) {
+		q := newSIPDialQueue(&config.SIPConfig{MaxParallelDials: 1})
+		release, err := q.Acquire(context.Background(), sipTrunkID1)
+		require.NoError(t, err)
+		release()
+		release, err = q.Acquire(context.Background(), sipTrunkID2)
+		require.NoError(t, err)
+		defer release()
+
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		require.Len(t, q.trunks, 1)
+		require.Contains(t, q.trunks, sipTrunkID2)
+	})
+}
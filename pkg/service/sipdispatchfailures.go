@@ -0,0 +1,45 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// SIPDispatchFailure is an inbound call that was rejected because no dispatch rule matched it.
+// Numbers are redacted according to the log_numbers setting.
+type SIPDispatchFailure struct {
+	Time          time.Time `json:"time"`
+	SIPTrunkID    string    `json:"sip_trunk_id,omitempty"`
+	CallingNumber string    `json:"calling_number"`
+	CalledNumber  string    `json:"called_number"`
+	Reason        string    `json:"reason"`
+}
+
+// ListSIPDispatchFailures returns recent inbound calls that matched a trunk but no dispatch rule, newest first.
+// The history is kept in the store and shared by every node.
+func (s *IOInfoService) ListSIPDispatchFailures(ctx context.Context) ([]*SIPDispatchFailure, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.sipConf == nil || s.sipConf.DispatchFailureHistory <= 0 {
+		return nil, nil
+	}
+	if s.ss == nil {
+		return nil, ErrSIPNotConnected
+	}
+	return s.ss.ListSIPDispatchFailures(ctx)
+}
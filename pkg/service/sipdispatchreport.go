@@ -0,0 +1,241 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+)
+
+// SIPDispatchRuleActivity is tracked for each dispatch rule as calls come in.
+// Zero times mean the rule has not matched a call, its room has not been found missing, or no report has seen it.
+type SIPDispatchRuleActivity struct {
+	LastMatched      time.Time
+	RoomMissingSince time.Time
+	FirstSeen        time.Time
+}
+
+type SIPDispatchRuleReportOptions struct {
+	// report rules that matched no call for this long, 0 disables the check. rules that never matched a call
+	// are measured from the first report that listed them
+	IdleFor time.Duration
+	// report rules whose room has not existed for this long. rooms are only checked for rules with
+	// a fixed room name, and only when set
+	RoomMissingFor time.Duration
+}
+
+// SIPDispatchRuleReport describes a dispatch rule that may no longer be needed.
+type SIPDispatchRuleReport struct {
+	SipDispatchRuleId string
+	TrunkIds          []string
+	// fixed room the rule sends calls to, empty for individual rules
+	RoomName         string
+	LastMatched      time.Time
+	FirstSeen        time.Time
+	RoomMissingSince time.Time
+	Idle             bool
+	RoomMissing      bool
+}
+
+// ReportSIPDispatchRules lists dispatch rules that matched no calls recently, or whose room no longer exists.
+// Nothing is deleted. Matches are only tracked from the time this server version was deployed, a rule is first seen
+// by the first report that lists it, and the time a room went missing is the first report that found it missing,
+// so a rule is never reported sooner than it should be. Checking rooms requires room list permission.
+func (s *SIPService) ReportSIPDispatchRules(ctx context.Context, opts SIPDispatchRuleReportOptions) ([]*SIPDispatchRuleReport, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+
+	rules, err := s.store.ListSIPDispatchRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.store.LoadSIPDispatchRuleActivity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing map[string]bool
+	if opts.RoomMissingFor > 0 && s.roomService != nil {
+		if existing, err = s.existingSIPRooms(ctx, rules); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	var unseen []string
+	var out []*SIPDispatchRuleReport
+	for _, rule := range rules {
+		a := activity[rule.SipDispatchRuleId]
+		if a == nil {
+			a = &SIPDispatchRuleActivity{}
+		}
+		// a rule existed when it matched a call, so the first time is only needed for rules without matches
+		firstSeen := a.FirstSeen
+		if firstSeen.IsZero() && a.LastMatched.IsZero() {
+			firstSeen = now
+			unseen = append(unseen, rule.SipDispatchRuleId)
+		}
+		r := &SIPDispatchRuleReport{
+			SipDispatchRuleId: rule.SipDispatchRuleId,
+			TrunkIds:          rule.TrunkIds,
+			RoomName:          sipStaticRoom(rule),
+			LastMatched:       a.LastMatched,
+			FirstSeen:         firstSeen,
+		}
+		if opts.IdleFor > 0 {
+			// a rule cannot have been idle for longer than it has existed
+			idleSince := a.LastMatched
+			if firstSeen.After(idleSince) {
+				idleSince = firstSeen
+			}
+			r.Idle = now.Sub(idleSince) >= opts.IdleFor
+		}
+
+		if existing != nil && r.RoomName != "" {
+			missingSince := a.RoomMissingSince
+			switch {
+			case existing[r.RoomName]:
+				missingSince = time.Time{}
+			case missingSince.IsZero():
+				missingSince = now
+			}
+			if !missingSince.Equal(a.RoomMissingSince) {
+				if err := s.store.SetSIPDispatchRuleRoomMissing(ctx, rule.SipDispatchRuleId, missingSince); err != nil {
+					logger.Warnw("could not record missing sip room", err, "sipDispatchRuleID", rule.SipDispatchRuleId)
+				}
+			}
+			r.RoomMissingSince = missingSince
+			r.RoomMissing = !missingSince.IsZero() && now.Sub(missingSince) >= opts.RoomMissingFor
+		}
+
+		if r.Idle || r.RoomMissing {
+			out = append(out, r)
+		}
+	}
+	if err := s.store.MarkSIPDispatchRulesSeen(ctx, unseen, now); err != nil {
+		logger.Warnw("could not record new sip dispatch rules", err)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SipDispatchRuleId < out[j].SipDispatchRuleId })
+	return out, nil
+}
+
+func (s *SIPService) existingSIPRooms(ctx context.Context, rules []*livekit.SIPDispatchRuleInfo) (map[string]bool, error) {
+	var names []string
+	for _, rule := range rules {
+		if name := sipStaticRoom(rule); name != "" {
+			names = append(names, name)
+		}
+	}
+	existing := make(map[string]bool)
+	if len(names) == 0 {
+		return existing, nil
+	}
+	res, err := s.roomService.ListRooms(ctx, &livekit.ListRoomsRequest{Names: names})
+	if err != nil {
+		return nil, err
+	}
+	for _, room := range res.Rooms {
+		existing[room.Name] = true
+	}
+	return existing, nil
+}
+
+// sipStaticRoom returns the room a dispatch rule always sends calls to, or an empty string for individual rules.
+func sipStaticRoom(rule *livekit.SIPDispatchRuleInfo) string {
+	switch r := rule.GetRule().GetRule().(type) {
+	case *livekit.SIPDispatchRule_DispatchRuleDirect:
+		return r.DispatchRuleDirect.GetRoomName()
+	case *livekit.SIPDispatchRule_DispatchRulePin:
+		return r.DispatchRulePin.GetRoomName()
+	}
+	return ""
+}
+
+const (
+	sipRuleMatchFlushInterval = time.Second
+	sipRuleMatchWriteTimeout  = 2 * time.Second
+)
+
+// sipRuleMatches collects dispatch rule matches, so that they are written to the store in the background instead of
+// on the call setup path. Only the latest match of each rule is kept, so a burst of calls costs one write per rule.
+type sipRuleMatches struct {
+	mu      sync.Mutex
+	pending map[string]time.Time
+}
+
+func newSIPRuleMatches() *sipRuleMatches {
+	return &sipRuleMatches{pending: make(map[string]time.Time)}
+}
+
+func (m *sipRuleMatches) add(sipDispatchRuleID string, at time.Time) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending[sipDispatchRuleID] = at
+}
+
+func (m *sipRuleMatches) take() map[string]time.Time {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := m.pending
+	m.pending = make(map[string]time.Time)
+	return pending
+}
+
+func (s *IOInfoService) recordSIPDispatchRuleMatch(sipDispatchRuleID string) {
+	s.sipMatches.add(sipDispatchRuleID, time.Now())
+}
+
+func (s *IOInfoService) sipRuleMatchWorker() {
+	ticker := time.NewTicker(sipRuleMatchFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.shutdown:
+			s.flushSIPDispatchRuleMatches()
+			return
+		case <-ticker.C:
+			s.flushSIPDispatchRuleMatches()
+		}
+	}
+}
+
+// flushSIPDispatchRuleMatches writes collected matches. Activity is only used for reports, so failed writes are
+// logged and dropped.
+func (s *IOInfoService) flushSIPDispatchRuleMatches() {
+	for id, at := range s.sipMatches.take() {
+		ctx, cancel := context.WithTimeout(context.Background(), sipRuleMatchWriteTimeout)
+		err := s.ss.RecordSIPDispatchRuleMatch(ctx, id, at)
+		cancel()
+		if err != nil {
+			logger.Warnw("could not record sip dispatch rule match", err, "sipDispatchRuleID", id)
+		}
+	}
+}
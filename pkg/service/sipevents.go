@@ -0,0 +1,62 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+)
+
+const (
+	SIPParticipantEventCreated = "created"
+	SIPParticipantEventDeleted = "deleted"
+)
+
+// SIPParticipantEvent is an entry in the timeline of a SIP call.
+type SIPParticipantEvent struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// GetSIPParticipantEvents returns the recorded events of a participant, oldest first.
+// Events are kept after the participant is deleted, until the configured TTL expires.
+func (s *SIPService) GetSIPParticipantEvents(ctx context.Context, sipParticipantID string) ([]*SIPParticipantEvent, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+	if err := sipValidateID("sip_participant_id", sipParticipantID, utils.SIPParticipantPrefix); err != nil {
+		return nil, err
+	}
+	return s.store.ListSIPParticipantEvents(ctx, sipParticipantID)
+}
+
+// recordSIPParticipantEvent appends an event to the participant's timeline.
+// Failures are only logged, the timeline is not worth failing the call for.
+func (s *SIPService) recordSIPParticipantEvent(ctx context.Context, sipParticipantID, eventType, detail string, log logger.Logger) {
+	if s.conf.ParticipantEventLimit <= 0 {
+		return
+	}
+	event := &SIPParticipantEvent{Time: time.Now(), Type: eventType, Detail: detail}
+	if err := s.store.AppendSIPParticipantEvent(ctx, sipParticipantID, event, s.conf.ParticipantEventLimit, s.conf.ParticipantEventTTL); err != nil {
+		log.Warnw("could not record SIP participant event", err, "event", eventType)
+	}
+}
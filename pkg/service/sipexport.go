@@ -0,0 +1,363 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/psrpc"
+)
+
+const (
+	SIPImportCreated = "created"
+	SIPImportUpdated = "updated"
+	SIPImportFailed  = "failed"
+)
+
+// SIPConfigDocument holds all SIP trunks and dispatch rules, for backup or moving between environments.
+type SIPConfigDocument struct {
+	Trunks        []*livekit.SIPTrunkInfo
+	DispatchRules []*livekit.SIPDispatchRuleInfo
+}
+
+type sipConfigDocumentJSON struct {
+	Trunks        []json.RawMessage `json:"trunks"`
+	DispatchRules []json.RawMessage `json:"dispatch_rules"`
+}
+
+func (d *SIPConfigDocument) MarshalJSON() ([]byte, error) {
+	var out sipConfigDocumentJSON
+	for _, t := range d.Trunks {
+		b, err := protojson.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		out.Trunks = append(out.Trunks, b)
+	}
+	for _, r := range d.DispatchRules {
+		b, err := protojson.Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		out.DispatchRules = append(out.DispatchRules, b)
+	}
+	return json.Marshal(out)
+}
+
+func (d *SIPConfigDocument) UnmarshalJSON(data []byte) error {
+	var in sipConfigDocumentJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	d.Trunks, d.DispatchRules = nil, nil
+	for _, b := range in.Trunks {
+		t := &livekit.SIPTrunkInfo{}
+		if err := protojson.Unmarshal(b, t); err != nil {
+			return err
+		}
+		d.Trunks = append(d.Trunks, t)
+	}
+	for _, b := range in.DispatchRules {
+		r := &livekit.SIPDispatchRuleInfo{}
+		if err := protojson.Unmarshal(b, r); err != nil {
+			return err
+		}
+		d.DispatchRules = append(d.DispatchRules, r)
+	}
+	return nil
+}
+
+type SIPImportOptions struct {
+	// validate the document and report what would change, without storing anything
+	DryRun bool
+	// keep the IDs from the document, updating existing items with the same ID.
+	// otherwise every item gets a new ID and dispatch rules are remapped to the new trunk IDs
+	PreserveIDs bool
+}
+
+type SIPImportResult struct {
+	// SourceID is the ID in the imported document, ID is the ID of the stored item
+	SourceID string
+	ID       string
+	Action   string
+	Error    error
+}
+
+type SIPImportResults struct {
+	Trunks        []*SIPImportResult
+	DispatchRules []*SIPImportResult
+}
+
+// ExportSIPConfig returns all trunks and dispatch rules. Trunk passwords are cleared when redactCredentials is set.
+func (s *SIPService) ExportSIPConfig(ctx context.Context, redactCredentials bool) (*SIPConfigDocument, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+
+	release, err := s.listBudget.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	trunks, err := s.store.ListSIPTrunk(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.store.ListSIPDispatchRule(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// sorted so that exports of the same config are identical
+	sort.Slice(trunks, func(i, j int) bool { return trunks[i].SipTrunkId < trunks[j].SipTrunkId })
+	sort.Slice(rules, func(i, j int) bool { return rules[i].SipDispatchRuleId < rules[j].SipDispatchRuleId })
+
+	doc := &SIPConfigDocument{DispatchRules: rules}
+	for _, t := range trunks {
+		if redactCredentials && t.Password != "" {
+			t = proto.Clone(t).(*livekit.SIPTrunkInfo)
+			t.Password = ""
+		}
+		doc.Trunks = append(doc.Trunks, t)
+	}
+	return doc, nil
+}
+
+// ImportSIPConfig creates or updates trunks and dispatch rules from a document, reporting the outcome of each item.
+// A failed item does not stop the import. Items that succeed are stored in a single transaction, so other readers
+// see either none or all of them. When an existing trunk is updated from a document with redacted credentials,
+// its stored password is kept.
+func (s *SIPService) ImportSIPConfig(ctx context.Context, doc *SIPConfigDocument, opts SIPImportOptions) (*SIPImportResults, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+
+	// new IDs are chosen once, so that retrying an import that was applied does not create duplicates
+	ids := newSIPImportIDs(doc)
+	if opts.DryRun {
+		return s.importSIPConfig(ctx, sipDryRunTxn{s.store}, doc, ids, opts), nil
+	}
+	var res *SIPImportResults
+	err := s.store.RunSIPTxn(ctx, func(tx SIPTxn) error {
+		// the transaction may be retried, results are rebuilt from scratch each time
+		res = s.importSIPConfig(ctx, tx, doc, ids, opts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type sipImportIDs struct {
+	trunks []string
+	rules  []string
+}
+
+func newSIPImportIDs(doc *SIPConfigDocument) *sipImportIDs {
+	ids := &sipImportIDs{}
+	for range doc.Trunks {
+		ids.trunks = append(ids.trunks, utils.NewGuid(utils.SIPTrunkPrefix))
+	}
+	for range doc.DispatchRules {
+		ids.rules = append(ids.rules, utils.NewGuid(utils.SIPDispatchRulePrefix))
+	}
+	return ids
+}
+
+func (s *SIPService) importSIPConfig(ctx context.Context, tx SIPTxn, doc *SIPConfigDocument, ids *sipImportIDs, opts SIPImportOptions) *SIPImportResults {
+	res := &SIPImportResults{}
+	// source ID => stored ID, for trunks that were imported
+	trunkIDs := make(map[string]string)
+	// source IDs of trunks that failed, so that rules using them fail too
+	failedTrunks := make(map[string]bool)
+	// existing trunks, replaced or extended as the import goes, for the conflict check
+	trunks, listErr := tx.ListSIPTrunk(ctx)
+	// items created so far are not visible until the transaction completes, but still count towards the quota
+	pending := 0
+	for i, t := range doc.Trunks {
+		info := proto.Clone(t).(*livekit.SIPTrunkInfo)
+		applied := false
+		r := &SIPImportResult{SourceID: t.SipTrunkId, Action: SIPImportCreated}
+		res.Trunks = append(res.Trunks, r)
+		failedTrunks[t.SipTrunkId] = true
+
+		if listErr != nil {
+			r.Action, r.Error = SIPImportFailed, listErr
+			continue
+		}
+		if opts.PreserveIDs && info.SipTrunkId != "" {
+			if err := sipValidateID("sip_trunk_id", info.SipTrunkId, utils.SIPTrunkPrefix); err != nil {
+				r.Action, r.Error = SIPImportFailed, err
+				continue
+			}
+			existing, err := tx.LoadSIPTrunk(ctx, info.SipTrunkId)
+			switch err {
+			case nil:
+				r.Action = SIPImportUpdated
+				if info.Password == "" && info.Username == existing.Username {
+					info.Password = existing.Password
+				}
+			case ErrSIPTrunkNotFound:
+			default:
+				r.Action, r.Error = SIPImportFailed, err
+				continue
+			}
+		} else {
+			info.SipTrunkId = ids.trunks[i]
+			existing, err := tx.LoadSIPTrunk(ctx, info.SipTrunkId)
+			applied = err == nil && existing != nil
+		}
+		r.ID = info.SipTrunkId
+
+		req := sipTrunkRequest(info)
+		problems := sipValidateTrunkFields(req)
+		problems = append(problems, sipTrunkConflicts(req, info.SipTrunkId, trunks)...)
+		if err := sipTrunkProblemsError(problems); err != nil {
+			r.Action, r.Error = SIPImportFailed, err
+			continue
+		}
+
+		// items stored by an earlier attempt are already counted by the store
+		if r.Action == SIPImportCreated && !applied {
+			if err := s.checkTrunkQuota(ctx, tx, pending+1); err != nil {
+				r.Action, r.Error = SIPImportFailed, err
+				continue
+			}
+			pending++
+		}
+		if err := tx.StoreSIPTrunk(ctx, info); err != nil {
+			r.Action, r.Error = SIPImportFailed, err
+			continue
+		}
+		trunkIDs[t.SipTrunkId] = info.SipTrunkId
+		delete(failedTrunks, t.SipTrunkId)
+		trunks = sipReplaceTrunk(trunks, info)
+	}
+
+	pending = 0
+	for i, d := range doc.DispatchRules {
+		info := proto.Clone(d).(*livekit.SIPDispatchRuleInfo)
+		applied := false
+		r := &SIPImportResult{SourceID: d.SipDispatchRuleId, Action: SIPImportCreated}
+		res.DispatchRules = append(res.DispatchRules, r)
+
+		if err := sipNormalizeDispatchRule(info.Rule); err != nil {
+			r.Action, r.Error = SIPImportFailed, psrpc.NewError(psrpc.InvalidArgument, err)
+			continue
+		}
+		if id := sipFirstFailedTrunk(info.TrunkIds, failedTrunks); id != "" {
+			r.Action, r.Error = SIPImportFailed, psrpc.NewErrorf(psrpc.FailedPrecondition, "trunk %s was not imported", id)
+			continue
+		}
+		if !opts.PreserveIDs {
+			for i, id := range info.TrunkIds {
+				if newID, ok := trunkIDs[id]; ok {
+					info.TrunkIds[i] = newID
+				}
+			}
+		}
+
+		if err := sipValidateTrunkIDs(info.TrunkIds); err != nil {
+			r.Action, r.Error = SIPImportFailed, err
+			continue
+		}
+		if opts.PreserveIDs && info.SipDispatchRuleId != "" {
+			if err := sipValidateID("sip_dispatch_rule_id", info.SipDispatchRuleId, utils.SIPDispatchRulePrefix); err != nil {
+				r.Action, r.Error = SIPImportFailed, err
+				continue
+			}
+			switch _, err := tx.LoadSIPDispatchRule(ctx, info.SipDispatchRuleId); err {
+			case nil:
+				r.Action = SIPImportUpdated
+			case ErrSIPDispatchRuleNotFound:
+			default:
+				r.Action, r.Error = SIPImportFailed, err
+				continue
+			}
+		} else {
+			info.SipDispatchRuleId = ids.rules[i]
+			existing, err := tx.LoadSIPDispatchRule(ctx, info.SipDispatchRuleId)
+			applied = err == nil && existing != nil
+		}
+		r.ID = info.SipDispatchRuleId
+
+		if r.Action == SIPImportCreated && !applied {
+			if err := s.checkDispatchRuleQuota(ctx, tx, pending+1); err != nil {
+				r.Action, r.Error = SIPImportFailed, err
+				continue
+			}
+			pending++
+		}
+		if err := tx.StoreSIPDispatchRule(ctx, info); err != nil {
+			r.Action, r.Error = SIPImportFailed, err
+		}
+	}
+	return res
+}
+
+func sipReplaceTrunk(trunks []*livekit.SIPTrunkInfo, info *livekit.SIPTrunkInfo) []*livekit.SIPTrunkInfo {
+	for i, t := range trunks {
+		if t.SipTrunkId == info.SipTrunkId {
+			trunks[i] = info
+			return trunks
+		}
+	}
+	return append(trunks, info)
+}
+
+func sipFirstFailedTrunk(ids []string, failed map[string]bool) string {
+	for _, id := range ids {
+		if failed[id] {
+			return id
+		}
+	}
+	return ""
+}
+
+// sipDryRunTxn reads from the store and discards all changes.
+type sipDryRunTxn struct {
+	SIPTxn
+}
+
+func (sipDryRunTxn) StoreSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error { return nil }
+
+func (sipDryRunTxn) DeleteSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error { return nil }
+
+func (sipDryRunTxn) StoreSIPDispatchRule(ctx context.Context, info *livekit.SIPDispatchRuleInfo) error {
+	return nil
+}
+
+func (sipDryRunTxn) DeleteSIPDispatchRule(ctx context.Context, info *livekit.SIPDispatchRuleInfo) error {
+	return nil
+}
+
+func (sipDryRunTxn) StoreSIPParticipant(ctx context.Context, info *livekit.SIPParticipantInfo) error {
+	return nil
+}
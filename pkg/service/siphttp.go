@@ -0,0 +1,490 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/psrpc"
+)
+
+// import documents hold every trunk and rule, so requests are allowed to be larger than typical API calls
+const sipHTTPMaxRequestSize = 8 << 20
+
+// SIPHTTPHandler serves the SIP operations that have no method in the SIP Twirp service, under /sip/.
+// Every operation is a POST with a JSON body and a JSON response, and errors are written in the Twirp format.
+// Callers authenticate with an API token, as for Twirp, and each operation checks SIP admin permission.
+type SIPHTTPHandler struct {
+	sip *SIPService
+	io  *IOInfoService
+	mux *http.ServeMux
+}
+
+func NewSIPHTTPHandler(sip *SIPService, io *IOInfoService) *SIPHTTPHandler {
+	h := &SIPHTTPHandler{
+		sip: sip,
+		io:  io,
+		mux: http.NewServeMux(),
+	}
+	h.handle("/sip/config/export", h.exportConfig)
+	h.handle("/sip/config/import", h.importConfig)
+	h.handle("/sip/trunk/validate", h.validateTrunk)
+	h.handle("/sip/trunk/batch_create", h.createTrunks)
+	h.handle("/sip/trunk/update_inbound_numbers_regex", h.updateTrunkInboundNumbersRegex)
+	h.handle("/sip/dispatch_rule/batch_create", h.createDispatchRules)
+	h.handle("/sip/dispatch_failures", h.listDispatchFailures)
+	h.handle("/sip/dispatch_rule/report", h.reportDispatchRules)
+	h.handle("/sip/participant/events", h.getParticipantEvents)
+	h.handle("/sip/participant/batch_get", h.batchGetParticipants)
+	h.handle("/sip/scheduled_call/create", h.scheduleCall)
+	h.handle("/sip/scheduled_call/list", h.listScheduledCalls)
+	h.handle("/sip/scheduled_call/cancel", h.cancelScheduledCall)
+	return h
+}
+
+func (h *SIPHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// handle registers an operation. The operation decodes its own request from the body, which may be empty.
+func (h *SIPHTTPHandler) handle(path string, op func(ctx context.Context, body []byte) (interface{}, error)) {
+	h.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			_ = twirp.WriteError(w, twirp.NewError(twirp.BadRoute, "unsupported method "+r.Method))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, sipHTTPMaxRequestSize))
+		if err != nil {
+			_ = twirp.WriteError(w, twirp.NewError(twirp.Malformed, err.Error()))
+			return
+		}
+
+		res, err := op(r.Context(), body)
+		if err != nil {
+			// psrpc errors convert to Twirp errors with the matching status
+			_ = twirp.WriteError(w, err)
+			return
+		}
+		data, err := json.Marshal(res)
+		if err != nil {
+			_ = twirp.WriteError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
+
+func sipDecodeHTTPRequest(body []byte, req interface{}) error {
+	if len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, req); err != nil {
+		return twirp.NewError(twirp.Malformed, fmt.Sprintf("invalid request: %v", err))
+	}
+	return nil
+}
+
+// sipParseDuration parses an optional duration in Go syntax, such as "72h".
+func sipParseDuration(field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, twirp.InvalidArgumentError(field, fmt.Sprintf("%q is not a duration", value))
+	}
+	return d, nil
+}
+
+// sipDecodeProto decodes a protobuf message embedded in a JSON request.
+func sipDecodeProto(field string, data json.RawMessage, m proto.Message) error {
+	if len(data) == 0 {
+		return twirp.RequiredArgumentError(field)
+	}
+	if err := protojson.Unmarshal(data, m); err != nil {
+		return twirp.InvalidArgumentError(field, err.Error())
+	}
+	return nil
+}
+
+// sipProtoJSON encodes messages with protojson, which plain JSON encoding does not match for oneof fields.
+func sipProtoJSON[T proto.Message](msgs []T) ([]json.RawMessage, error) {
+	out := make([]json.RawMessage, 0, len(msgs))
+	for _, m := range msgs {
+		b, err := protojson.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// sipErrorString is used for per-item errors in responses, where a nil error is left out.
+func sipErrorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+type sipExportConfigRequest struct {
+	RedactCredentials bool `json:"redact_credentials"`
+}
+
+func (h *SIPHTTPHandler) exportConfig(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipExportConfigRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	return h.sip.ExportSIPConfig(ctx, req.RedactCredentials)
+}
+
+type sipImportConfigRequest struct {
+	Document    *SIPConfigDocument `json:"document"`
+	DryRun      bool               `json:"dry_run"`
+	PreserveIDs bool               `json:"preserve_ids"`
+}
+
+type sipImportResultJSON struct {
+	SourceID string `json:"source_id,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Action   string `json:"action"`
+	Error    string `json:"error,omitempty"`
+}
+
+type sipImportConfigResponse struct {
+	Trunks        []sipImportResultJSON `json:"trunks"`
+	DispatchRules []sipImportResultJSON `json:"dispatch_rules"`
+}
+
+func (h *SIPHTTPHandler) importConfig(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipImportConfigRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	if req.Document == nil {
+		return nil, twirp.RequiredArgumentError("document")
+	}
+	res, err := h.sip.ImportSIPConfig(ctx, req.Document, SIPImportOptions{DryRun: req.DryRun, PreserveIDs: req.PreserveIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	convert := func(results []*SIPImportResult) []sipImportResultJSON {
+		out := make([]sipImportResultJSON, 0, len(results))
+		for _, r := range results {
+			out = append(out, sipImportResultJSON{SourceID: r.SourceID, ID: r.ID, Action: r.Action, Error: sipErrorString(r.Error)})
+		}
+		return out
+	}
+	return &sipImportConfigResponse{
+		Trunks:        convert(res.Trunks),
+		DispatchRules: convert(res.DispatchRules),
+	}, nil
+}
+
+type sipDispatchFailuresResponse struct {
+	Failures []*SIPDispatchFailure `json:"failures"`
+}
+
+// listDispatchFailures returns the failures recorded by every node.
+func (h *SIPHTTPHandler) listDispatchFailures(ctx context.Context, body []byte) (interface{}, error) {
+	failures, err := h.io.ListSIPDispatchFailures(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if failures == nil {
+		failures = []*SIPDispatchFailure{}
+	}
+	return &sipDispatchFailuresResponse{Failures: failures}, nil
+}
+
+type sipParticipantEventsRequest struct {
+	SipParticipantID string `json:"sip_participant_id"`
+}
+
+type sipParticipantEventsResponse struct {
+	Events []*SIPParticipantEvent `json:"events"`
+}
+
+func (h *SIPHTTPHandler) getParticipantEvents(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipParticipantEventsRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	events, err := h.sip.GetSIPParticipantEvents(ctx, req.SipParticipantID)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []*SIPParticipantEvent{}
+	}
+	return &sipParticipantEventsResponse{Events: events}, nil
+}
+
+type sipBatchGetParticipantsRequest struct {
+	SipParticipantIDs []string `json:"sip_participant_ids"`
+}
+
+type sipBatchGetParticipantsResponse struct {
+	Items   []json.RawMessage `json:"items"`
+	Missing []string          `json:"missing"`
+}
+
+func (h *SIPHTTPHandler) batchGetParticipants(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipBatchGetParticipantsRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	res, err := h.sip.BatchGetSIPParticipant(ctx, req.SipParticipantIDs)
+	if err != nil {
+		return nil, err
+	}
+	items, err := sipProtoJSON(res.Items)
+	if err != nil {
+		return nil, err
+	}
+	missing := res.Missing
+	if missing == nil {
+		missing = []string{}
+	}
+	return &sipBatchGetParticipantsResponse{Items: items, Missing: missing}, nil
+}
+
+type sipReportDispatchRulesRequest struct {
+	IdleFor        string `json:"idle_for"`
+	RoomMissingFor string `json:"room_missing_for"`
+}
+
+type sipDispatchRuleReportJSON struct {
+	SipDispatchRuleId string    `json:"sip_dispatch_rule_id"`
+	TrunkIds          []string  `json:"trunk_ids,omitempty"`
+	RoomName          string    `json:"room_name,omitempty"`
+	LastMatched       time.Time `json:"last_matched"`
+	FirstSeen         time.Time `json:"first_seen"`
+	RoomMissingSince  time.Time `json:"room_missing_since"`
+	Idle              bool      `json:"idle"`
+	RoomMissing       bool      `json:"room_missing"`
+}
+
+type sipReportDispatchRulesResponse struct {
+	Rules []sipDispatchRuleReportJSON `json:"rules"`
+}
+
+func (h *SIPHTTPHandler) reportDispatchRules(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipReportDispatchRulesRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	var opts SIPDispatchRuleReportOptions
+	var err error
+	if opts.IdleFor, err = sipParseDuration("idle_for", req.IdleFor); err != nil {
+		return nil, err
+	}
+	if opts.RoomMissingFor, err = sipParseDuration("room_missing_for", req.RoomMissingFor); err != nil {
+		return nil, err
+	}
+
+	reports, err := h.sip.ReportSIPDispatchRules(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	res := &sipReportDispatchRulesResponse{Rules: make([]sipDispatchRuleReportJSON, 0, len(reports))}
+	for _, r := range reports {
+		res.Rules = append(res.Rules, sipDispatchRuleReportJSON(*r))
+	}
+	return res, nil
+}
+
+type sipScheduleCallRequest struct {
+	// a CreateSIPParticipantRequest
+	Request     json.RawMessage `json:"request"`
+	ScheduledAt time.Time       `json:"scheduled_at"`
+}
+
+func (h *SIPHTTPHandler) scheduleCall(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipScheduleCallRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	create := &livekit.CreateSIPParticipantRequest{}
+	if err := sipDecodeProto("request", req.Request, create); err != nil {
+		return nil, err
+	}
+	return h.sip.ScheduleSIPParticipant(ctx, create, req.ScheduledAt)
+}
+
+type sipListScheduledCallsResponse struct {
+	Calls []*SIPScheduledCall `json:"calls"`
+}
+
+func (h *SIPHTTPHandler) listScheduledCalls(ctx context.Context, body []byte) (interface{}, error) {
+	calls, err := h.sip.ListSIPScheduledCalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if calls == nil {
+		calls = []*SIPScheduledCall{}
+	}
+	return &sipListScheduledCallsResponse{Calls: calls}, nil
+}
+
+type sipCancelScheduledCallRequest struct {
+	ScheduledCallID string `json:"scheduled_call_id"`
+}
+
+func (h *SIPHTTPHandler) cancelScheduledCall(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipCancelScheduledCallRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	if err := h.sip.CancelSIPScheduledCall(ctx, req.ScheduledCallID); err != nil {
+		return nil, err
+	}
+	return struct{}{}, nil
+}
+
+type sipUpdateInboundNumbersRegexRequest struct {
+	SipTrunkID          string   `json:"sip_trunk_id"`
+	InboundNumbersRegex []string `json:"inbound_numbers_regex"`
+}
+
+func (h *SIPHTTPHandler) updateTrunkInboundNumbersRegex(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipUpdateInboundNumbersRegexRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	info, err := h.sip.UpdateSIPTrunkInboundNumbersRegex(ctx, req.SipTrunkID, req.InboundNumbersRegex)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(protojson.Format(info)), nil
+}
+
+type sipBatchCreateRequest struct {
+	// CreateSIPTrunkRequest or CreateSIPDispatchRuleRequest messages
+	Requests   []json.RawMessage `json:"requests"`
+	BestEffort bool              `json:"best_effort"`
+}
+
+type sipBatchCreateResponse struct {
+	// created items in request order, null for items that failed in best-effort mode
+	Items []json.RawMessage `json:"items"`
+	// errors by item index, only in best-effort mode
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// sipBatchCreateResult converts a batch create result. Best-effort failures are reported per item, so only other
+// errors fail the request.
+func sipBatchCreateResult[T proto.Message](items []T, err error) (interface{}, error) {
+	res := &sipBatchCreateResponse{}
+	var batchErr *SIPBatchError
+	if err != nil {
+		if errors.As(err, new(psrpc.Error)) || !errors.As(err, &batchErr) {
+			return nil, err
+		}
+		res.Errors = make(map[string]string, len(batchErr.Errors))
+		for i, err := range batchErr.Errors {
+			res.Errors[strconv.Itoa(i)] = err.Error()
+		}
+	}
+	res.Items = make([]json.RawMessage, 0, len(items))
+	for i, item := range items {
+		if _, failed := res.Errors[strconv.Itoa(i)]; failed {
+			res.Items = append(res.Items, json.RawMessage("null"))
+			continue
+		}
+		b, err := protojson.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		res.Items = append(res.Items, b)
+	}
+	return res, nil
+}
+
+func (h *SIPHTTPHandler) createTrunks(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipBatchCreateRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	reqs := make([]*livekit.CreateSIPTrunkRequest, 0, len(req.Requests))
+	for i, data := range req.Requests {
+		r := &livekit.CreateSIPTrunkRequest{}
+		if err := sipDecodeProto(fmt.Sprintf("requests[%d]", i), data, r); err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, r)
+	}
+	return sipBatchCreateResult(h.sip.CreateSIPTrunks(ctx, reqs, SIPBatchOptions{BestEffort: req.BestEffort}))
+}
+
+func (h *SIPHTTPHandler) createDispatchRules(ctx context.Context, body []byte) (interface{}, error) {
+	var req sipBatchCreateRequest
+	if err := sipDecodeHTTPRequest(body, &req); err != nil {
+		return nil, err
+	}
+	reqs := make([]*livekit.CreateSIPDispatchRuleRequest, 0, len(req.Requests))
+	for i, data := range req.Requests {
+		r := &livekit.CreateSIPDispatchRuleRequest{}
+		if err := sipDecodeProto(fmt.Sprintf("requests[%d]", i), data, r); err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, r)
+	}
+	return sipBatchCreateResult(h.sip.CreateSIPDispatchRules(ctx, reqs, SIPBatchOptions{BestEffort: req.BestEffort}))
+}
+
+type sipTrunkProblemJSON struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Warning bool   `json:"warning,omitempty"`
+}
+
+type sipValidateTrunkResponse struct {
+	Problems []sipTrunkProblemJSON `json:"problems"`
+}
+
+// validateTrunk takes a CreateSIPTrunkRequest in protojson form.
+func (h *SIPHTTPHandler) validateTrunk(ctx context.Context, body []byte) (interface{}, error) {
+	req := &livekit.CreateSIPTrunkRequest{}
+	if err := sipDecodeProto("request", body, req); err != nil {
+		return nil, err
+	}
+	problems, err := h.sip.ValidateSIPTrunk(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	res := &sipValidateTrunkResponse{Problems: make([]sipTrunkProblemJSON, 0, len(problems))}
+	for _, p := range problems {
+		res.Problems = append(res.Problems, sipTrunkProblemJSON(p))
+	}
+	return res, nil
+}
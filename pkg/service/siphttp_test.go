@@ -0,0 +1,269 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/service"
+)
+
+func sipHTTPCall(t *testing.T, h http.Handler, ctx context.Context, path, body string, out interface{}) int {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if out != nil && w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), out))
+	}
+	return w.Code
+}
+
+func TestSIPHTTPHandler(t *testing.T) {
+	t.Run("requires admin", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		h := service.NewSIPHTTPHandler(svc, nil)
+		code := sipHTTPCall(t, h, context.Background(), "/sip/config/export", "", nil)
+		require.Equal(t, http.StatusUnauthorized, code)
+		require.Equal(t, 0, store.ListSIPTrunkCallCount())
+	})
+
+	t.Run("post only", func(t *testing.T) {
+		svc, _ := newTestSIPService(config.SIPConfig{})
+		h := service.NewSIPHTTPHandler(svc, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sip/config/export", nil).WithContext(sipAdminContext()))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("config export and import", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.ListSIPTrunkReturns([]*livekit.SIPTrunkInfo{{SipTrunkId: "ST_aaa", OutboundNumber: "+15550001111", Password: "secret"}}, nil)
+		h := service.NewSIPHTTPHandler(svc, nil)
+
+		var doc service.SIPConfigDocument
+		code := sipHTTPCall(t, h, sipAdminContext(), "/sip/config/export", `{"redact_credentials": true}`, &doc)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, doc.Trunks, 1)
+		require.Empty(t, doc.Trunks[0].Password)
+
+		data, err := json.Marshal(map[string]interface{}{"document": &doc, "dry_run": true})
+		require.NoError(t, err)
+		var res struct {
+			Trunks []struct {
+				SourceID string `json:"source_id"`
+				Action   string `json:"action"`
+				Error    string `json:"error"`
+			} `json:"trunks"`
+		}
+		code = sipHTTPCall(t, h, sipAdminContext(), "/sip/config/import", string(data), &res)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.Trunks, 1)
+		require.Equal(t, "ST_aaa", res.Trunks[0].SourceID)
+		// conflicts with the stored trunk it was exported from
+		require.Equal(t, service.SIPImportFailed, res.Trunks[0].Action)
+		require.NotEmpty(t, res.Trunks[0].Error)
+		require.Equal(t, 0, store.StoreSIPTrunkCallCount())
+
+		code = sipHTTPCall(t, h, sipAdminContext(), "/sip/config/import", `{}`, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("dispatch failures", func(t *testing.T) {
+		svc, _ := newTestSIPService(config.SIPConfig{})
+		io, err := service.NewIOInfoService(nil, nil, nil, nil, nil, nil, &config.Config{}, nil)
+		require.NoError(t, err)
+		h := service.NewSIPHTTPHandler(svc, io)
+
+		require.Equal(t, http.StatusUnauthorized, sipHTTPCall(t, h, context.Background(), "/sip/dispatch_failures", "", nil))
+		var res struct {
+			Failures []json.RawMessage `json:"failures"`
+		}
+		require.Equal(t, http.StatusOK, sipHTTPCall(t, h, sipAdminContext(), "/sip/dispatch_failures", "", &res))
+		require.NotNil(t, res.Failures)
+		require.Empty(t, res.Failures)
+	})
+
+	t.Run("participant events", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.ListSIPParticipantEventsReturns([]*service.SIPParticipantEvent{{Type: service.SIPParticipantEventCreated}}, nil)
+		h := service.NewSIPHTTPHandler(svc, nil)
+
+		var res struct {
+			Events []*service.SIPParticipantEvent `json:"events"`
+		}
+		code := sipHTTPCall(t, h, sipAdminContext(), "/sip/participant/events", `{"sip_participant_id": "SP_aaa"}`, &res)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.Events, 1)
+		require.Equal(t, service.SIPParticipantEventCreated, res.Events[0].Type)
+		_, id := store.ListSIPParticipantEventsArgsForCall(0)
+		require.Equal(t, "SP_aaa", id)
+
+		code = sipHTTPCall(t, h, sipAdminContext(), "/sip/participant/events", `{"sip_participant_id": "bad"}`, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("participant batch get", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.LoadSIPParticipantsReturns([]*livekit.SIPParticipantInfo{{SipParticipantId: "SP_aaa"}}, nil)
+		h := service.NewSIPHTTPHandler(svc, nil)
+
+		var res struct {
+			Items   []json.RawMessage `json:"items"`
+			Missing []string          `json:"missing"`
+		}
+		code := sipHTTPCall(t, h, sipAdminContext(), "/sip/participant/batch_get", `{"sip_participant_ids": ["SP_aaa", "SP_bbb"]}`, &res)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.Items, 1)
+		info := &livekit.SIPParticipantInfo{}
+		require.NoError(t, protojson.Unmarshal(res.Items[0], info))
+		require.Equal(t, "SP_aaa", info.SipParticipantId)
+		require.Equal(t, []string{"SP_bbb"}, res.Missing)
+	})
+
+	t.Run("dispatch rule report", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.ListSIPDispatchRuleReturns([]*livekit.SIPDispatchRuleInfo{{SipDispatchRuleId: "SDR_aaa"}}, nil)
+		store.LoadSIPDispatchRuleActivityReturns(map[string]*service.SIPDispatchRuleActivity{
+			"SDR_aaa": {LastMatched: time.Now().Add(-30 * 24 * time.Hour)},
+		}, nil)
+		h := service.NewSIPHTTPHandler(svc, nil)
+
+		var res struct {
+			Rules []struct {
+				SipDispatchRuleID string `json:"sip_dispatch_rule_id"`
+				Idle              bool   `json:"idle"`
+			} `json:"rules"`
+		}
+		code := sipHTTPCall(t, h, sipAdminContext(), "/sip/dispatch_rule/report", `{"idle_for": "168h"}`, &res)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.Rules, 1)
+		require.Equal(t, "SDR_aaa", res.Rules[0].SipDispatchRuleID)
+		require.True(t, res.Rules[0].Idle)
+
+		code = sipHTTPCall(t, h, sipAdminContext(), "/sip/dispatch_rule/report", `{"idle_for": "a week"}`, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("scheduled calls", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		h := service.NewSIPHTTPHandler(svc, nil)
+
+		at := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		var call service.SIPScheduledCall
+		code := sipHTTPCall(t, h, sipAdminContext(), "/sip/scheduled_call/create",
+			`{"request": {"sip_trunk_id": "ST_aaa", "room_name": "campaign"}, "scheduled_at": "`+at+`"}`, &call)
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "campaign", call.Request.RoomName)
+		require.Equal(t, 1, store.StoreSIPScheduledCallCallCount())
+
+		store.ListSIPScheduledCallsReturns([]*service.SIPScheduledCall{&call}, nil)
+		var list struct {
+			Calls []*service.SIPScheduledCall `json:"calls"`
+		}
+		code = sipHTTPCall(t, h, sipAdminContext(), "/sip/scheduled_call/list", "", &list)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, list.Calls, 1)
+		require.Equal(t, call.ID, list.Calls[0].ID)
+
+		store.CancelSIPScheduledCallReturns(service.ErrSIPScheduledCallMissing)
+		code = sipHTTPCall(t, h, sipAdminContext(), "/sip/scheduled_call/cancel", `{"scheduled_call_id": "`+call.ID+`"}`, nil)
+		require.Equal(t, http.StatusNotFound, code)
+
+		code = sipHTTPCall(t, h, sipAdminContext(), "/sip/scheduled_call/create", `{"scheduled_at": "`+at+`"}`, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("update inbound numbers regex", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		store.LoadSIPTrunkReturns(&livekit.SIPTrunkInfo{SipTrunkId: "ST_aaa"}, nil)
+		h := service.NewSIPHTTPHandler(svc, nil)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/sip/trunk/update_inbound_numbers_regex",
+			strings.NewReader(`{"sip_trunk_id": "ST_aaa", "inbound_numbers_regex": ["^\\+1555"]}`)).WithContext(sipAdminContext()))
+		require.Equal(t, http.StatusOK, w.Code)
+		info := &livekit.SIPTrunkInfo{}
+		require.NoError(t, protojson.Unmarshal(w.Body.Bytes(), info))
+		require.Equal(t, []string{`^\+1555`}, info.InboundNumbersRegex)
+
+		code := sipHTTPCall(t, h, sipAdminContext(), "/sip/trunk/update_inbound_numbers_regex", `{"sip_trunk_id": "ST_aaa", "inbound_numbers_regex": ["("]}`, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("batch create", func(t *testing.T) {
+		svc, store := newTestSIPService(config.SIPConfig{})
+		h := service.NewSIPHTTPHandler(svc, nil)
+
+		var res struct {
+			Items  []json.RawMessage `json:"items"`
+			Errors map[string]string `json:"errors"`
+		}
+		code := sipHTTPCall(t, h, sipAdminContext(), "/sip/trunk/batch_create", `{
+			"requests": [{"outbound_number": "+15550001111"}, {"inbound_numbers_regex": ["("]}],
+			"best_effort": true
+		}`, &res)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.Items, 2)
+		info := &livekit.SIPTrunkInfo{}
+		require.NoError(t, protojson.Unmarshal(res.Items[0], info))
+		require.Equal(t, "+15550001111", info.OutboundNumber)
+		require.Equal(t, "null", string(res.Items[1]))
+		require.Contains(t, res.Errors["1"], "inbound_numbers_regex")
+		require.Equal(t, 1, store.StoreSIPTrunkCallCount())
+
+		// atomic batches fail as a whole
+		code = sipHTTPCall(t, h, sipAdminContext(), "/sip/trunk/batch_create", `{
+			"requests": [{"outbound_number": "+15550002222"}, {"inbound_numbers_regex": ["("]}]
+		}`, nil)
+		require.Equal(t, http.StatusBadRequest, code)
+		require.Equal(t, 1, store.StoreSIPTrunkCallCount())
+
+		code = sipHTTPCall(t, h, sipAdminContext(), "/sip/dispatch_rule/batch_create", `{
+			"requests": [{"rule": {"dispatch_rule_direct": {"room_name": "support"}}}]
+		}`, &res)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.Items, 1)
+		rule := &livekit.SIPDispatchRuleInfo{}
+		require.NoError(t, protojson.Unmarshal(res.Items[0], rule))
+		require.Equal(t, "support", rule.Rule.GetDispatchRuleDirect().RoomName)
+	})
+
+	t.Run("validate trunk", func(t *testing.T) {
+		svc, _ := newTestSIPService(config.SIPConfig{})
+		h := service.NewSIPHTTPHandler(svc, nil)
+
+		var res struct {
+			Problems []struct {
+				Field string `json:"field"`
+			} `json:"problems"`
+		}
+		code := sipHTTPCall(t, h, sipAdminContext(), "/sip/trunk/validate", `{"outbound_address": "sip://example.com"}`, &res)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, res.Problems, 1)
+		require.Equal(t, "outbound_address", res.Problems[0].Field)
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// sipRateLimiter is a token bucket rate limiter keyed by an arbitrary ID (e.g. SIP participant ID).
+type sipRateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*sipTokenBucket
+	lastPrune time.Time
+}
+
+type sipTokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newSIPRateLimiter creates a limiter allowing rate requests per second with the given burst.
+// A rate of 0 disables limiting.
+func newSIPRateLimiter(rate float64, burst int) *sipRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &sipRateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*sipTokenBucket),
+	}
+}
+
+// Allow reports whether a request for the given key is allowed now, consuming a token if it is.
+func (l *sipRateLimiter) Allow(key string) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.buckets[key]
+	if b == nil {
+		b = &sipTokenBucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens += now.Sub(b.updated).Seconds() * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.updated = now
+	}
+	l.pruneLocked(now)
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// pruneLocked drops buckets that have been idle long enough to be full again.
+func (l *sipRateLimiter) pruneLocked(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.lastPrune) < refill {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) > refill {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastPrune = now
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSIPRateLimiter(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		l := newSIPRateLimiter(0, 0)
+		for i := 0; i < 100; i++ {
+			require.True(t, l.Allow("a"))
+		}
+	})
+
+	t.Run("burst", func(t *testing.T) {
+		l := newSIPRateLimiter(1, 3)
+		for i := 0; i < 3; i++ {
+			require.True(t, l.Allow("a"))
+		}
+		require.False(t, l.Allow("a"))
+		// keys are independent
+		require.True(t, l.Allow("b"))
+	})
+
+	t.Run("refill", func(t *testing.T) {
+		l := newSIPRateLimiter(50, 1)
+		require.True(t, l.Allow("a"))
+		require.False(t, l.Allow("a"))
+		time.Sleep(30 * time.Millisecond)
+		require.True(t, l.Allow("a"))
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+const (
+	sipStoreAttempts     = 3
+	sipStoreRetryBackoff = 100 * time.Millisecond
+)
+
+// sipRetryStore retries SIP store writes that fail with transient errors, such as during a Redis failover.
+// Writes store objects under IDs chosen before the first attempt, so repeating a write that landed despite
+// the error converges on the same state. Event appends are not retried, since they are not idempotent.
+type sipRetryStore struct {
+	SIPStore
+	backoff time.Duration
+}
+
+func newSIPRetryStore(store SIPStore) *sipRetryStore {
+	return &sipRetryStore{SIPStore: store, backoff: sipStoreRetryBackoff}
+}
+
+func (s *sipRetryStore) retry(ctx context.Context, op string, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || !sipStoreRetryable(err) {
+			break
+		}
+		if attempt == sipStoreAttempts {
+			prometheus.SIPStoreRetry(op, "failed")
+			return err
+		}
+		prometheus.SIPStoreRetry(op, "retried")
+
+		// full jitter, so that servers recovering from the same failover do not retry in lockstep
+		wait := time.Duration(rand.Int63n(int64(s.backoff) << (attempt - 1)))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+	}
+	return err
+}
+
+// sipStoreRetryable reports whether a store error is likely to go away on its own.
+// Errors from the service itself (not found, conflicts, quotas) and cancelled requests are never retried.
+func sipStoreRetryable(err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	// redis errors that are returned during failover and startup
+	msg := err.Error()
+	for _, prefix := range []string{"LOADING ", "READONLY ", "MASTERDOWN ", "CLUSTERDOWN ", "TRYAGAIN "} {
+		if strings.HasPrefix(msg, prefix) || strings.Contains(msg, ": "+prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sipRetryStore) StoreSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error {
+	return s.retry(ctx, "store_trunk", func() error { return s.SIPStore.StoreSIPTrunk(ctx, info) })
+}
+
+func (s *sipRetryStore) DeleteSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error {
+	return s.retry(ctx, "delete_trunk", func() error { return s.SIPStore.DeleteSIPTrunk(ctx, info) })
+}
+
+func (s *sipRetryStore) StoreSIPDispatchRule(ctx context.Context, info *livekit.SIPDispatchRuleInfo) error {
+	return s.retry(ctx, "store_dispatch_rule", func() error { return s.SIPStore.StoreSIPDispatchRule(ctx, info) })
+}
+
+func (s *sipRetryStore) DeleteSIPDispatchRule(ctx context.Context, info *livekit.SIPDispatchRuleInfo) error {
+	return s.retry(ctx, "delete_dispatch_rule", func() error { return s.SIPStore.DeleteSIPDispatchRule(ctx, info) })
+}
+
+func (s *sipRetryStore) StoreSIPParticipant(ctx context.Context, info *livekit.SIPParticipantInfo) error {
+	return s.retry(ctx, "store_participant", func() error { return s.SIPStore.StoreSIPParticipant(ctx, info) })
+}
+
+func (s *sipRetryStore) DeleteSIPParticipant(ctx context.Context, info *livekit.SIPParticipantInfo) error {
+	return s.retry(ctx, "delete_participant", func() error { return s.SIPStore.DeleteSIPParticipant(ctx, info) })
+}
+
+// RunSIPTxn retries the whole transaction. Callers must make fn idempotent: a retried fn can see the changes
+// of an earlier attempt that was applied even though it reported an error.
+func (s *sipRetryStore) RunSIPTxn(ctx context.Context, fn func(tx SIPTxn) error) error {
+	return s.retry(ctx, "txn", func() error { return s.SIPStore.RunSIPTxn(ctx, fn) })
+}
@@ -0,0 +1,142 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+// sipLossyStore applies every transaction, then reports an error for the first failures of them,
+// like a connection that drops before the reply arrives.
+type sipLossyStore struct {
+	SIPStore
+	mu       sync.Mutex
+	trunks   map[string]*livekit.SIPTrunkInfo
+	err      error
+	failures int
+	calls    int
+}
+
+func (s *sipLossyStore) StoreSIPTrunk(ctx context.Context, info *livekit.SIPTrunkInfo) error {
+	s.trunks[info.SipTrunkId] = info
+	return nil
+}
+
+func (s *sipLossyStore) LoadSIPTrunk(ctx context.Context, id string) (*livekit.SIPTrunkInfo, error) {
+	if t, ok := s.trunks[id]; ok {
+		return t, nil
+	}
+	return nil, ErrSIPTrunkNotFound
+}
+
+func (s *sipLossyStore) ListSIPTrunk(ctx context.Context) ([]*livekit.SIPTrunkInfo, error) {
+	var out []*livekit.SIPTrunkInfo
+	for _, t := range s.trunks {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (s *sipLossyStore) RunSIPTxn(ctx context.Context, fn func(tx SIPTxn) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if err := fn(s); err != nil {
+		return err
+	}
+	if s.failures > 0 {
+		s.failures--
+		return s.err
+	}
+	return nil
+}
+
+func TestSIPRetryStore(t *testing.T) {
+	ctx := WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{RoomCreate: true}})
+	newService := func(conf config.SIPConfig, store *sipLossyStore) *SIPService {
+		rs := newSIPRetryStore(store)
+		rs.backoff = time.Millisecond
+		return NewSIPService(&conf, "node", nil, nil, rs, nil, nil)
+	}
+
+	t.Run("create converges after lost reply", func(t *testing.T) {
+		store := &sipLossyStore{trunks: make(map[string]*livekit.SIPTrunkInfo), err: io.ErrUnexpectedEOF, failures: 2}
+		// the quota would reject a second copy of the trunk
+		svc := newService(config.SIPConfig{MaxTrunks: 1}, store)
+
+		trunk, err := svc.CreateSIPTrunk(ctx, &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550001111"})
+		require.NoError(t, err)
+		require.Equal(t, 3, store.calls)
+		require.Len(t, store.trunks, 1)
+		require.Contains(t, store.trunks, trunk.SipTrunkId)
+	})
+
+	t.Run("import converges after lost reply", func(t *testing.T) {
+		store := &sipLossyStore{trunks: make(map[string]*livekit.SIPTrunkInfo), err: io.EOF, failures: 1}
+		svc := newService(config.SIPConfig{MaxTrunks: 2}, store)
+
+		res, err := svc.ImportSIPConfig(ctx, &SIPConfigDocument{Trunks: []*livekit.SIPTrunkInfo{
+			{SipTrunkId: "ST_a", OutboundNumber: "+15550001111"},
+			{SipTrunkId: "ST_b", OutboundNumber: "+15550002222"},
+		}}, SIPImportOptions{})
+		require.NoError(t, err)
+		require.Len(t, store.trunks, 2)
+		for _, r := range res.Trunks {
+			require.NoError(t, r.Error)
+			require.Equal(t, SIPImportCreated, r.Action)
+			require.Contains(t, store.trunks, r.ID)
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		store := &sipLossyStore{trunks: make(map[string]*livekit.SIPTrunkInfo), err: io.EOF, failures: sipStoreAttempts}
+		svc := newService(config.SIPConfig{}, store)
+
+		_, err := svc.CreateSIPTrunk(ctx, &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550001111"})
+		require.ErrorIs(t, err, io.EOF)
+		require.Equal(t, sipStoreAttempts, store.calls)
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		for _, err := range []error{ErrSIPStoreConflict, context.Canceled, errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")} {
+			store := &sipLossyStore{trunks: make(map[string]*livekit.SIPTrunkInfo), err: err, failures: 1}
+			svc := newService(config.SIPConfig{}, store)
+
+			_, got := svc.CreateSIPTrunk(ctx, &livekit.CreateSIPTrunkRequest{OutboundNumber: "+15550001111"})
+			require.ErrorIs(t, got, err)
+			require.Equal(t, 1, store.calls)
+		}
+	})
+}
+
+func TestSIPStoreRetryable(t *testing.T) {
+	require.True(t, sipStoreRetryable(io.EOF))
+	require.True(t, sipStoreRetryable(errors.New("READONLY You can't write against a read only replica.")))
+	require.True(t, sipStoreRetryable(errors.New("LOADING Redis is loading the dataset in memory")))
+	require.False(t, sipStoreRetryable(ErrSIPTrunkNotFound))
+	require.False(t, sipStoreRetryable(context.DeadlineExceeded))
+}
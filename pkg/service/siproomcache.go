@@ -0,0 +1,102 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+const sipRoomLoadTimeout = 5 * time.Second
+
+// sipRoomCache remembers rooms recently found missing by the SIP dispatch path.
+// Concurrent lookups for the same room share a single call to the loader,
+// so a burst of inbound calls to one conference results in one store round trip.
+// Rooms that exist are not cached, so a deleted room is noticed by the next call without an invalidation event.
+type sipRoomCache struct {
+	ttl  time.Duration
+	load func(ctx context.Context, roomName livekit.RoomName) error
+
+	group singleflight.Group
+
+	mu        sync.Mutex
+	missing   map[livekit.RoomName]time.Time
+	lastPrune time.Time
+}
+
+func newSIPRoomCache(ttl time.Duration, load func(ctx context.Context, roomName livekit.RoomName) error) *sipRoomCache {
+	return &sipRoomCache{
+		ttl:     ttl,
+		load:    load,
+		missing: make(map[livekit.RoomName]time.Time),
+	}
+}
+
+// Validate checks that the room can be joined by a SIP participant.
+// Only ErrRoomNotFound is cached, so a room created after a failed lookup is picked up once the entry expires.
+func (c *sipRoomCache) Validate(ctx context.Context, roomName livekit.RoomName) error {
+	if c.ttl <= 0 {
+		return c.load(ctx, roomName)
+	}
+
+	c.mu.Lock()
+	expires, ok := c.missing[roomName]
+	c.mu.Unlock()
+	if ok && time.Now().Before(expires) {
+		return ErrRoomNotFound
+	}
+
+	// the load is shared by every caller waiting for the room, so it must not be cancelled with the first one.
+	// Each caller still stops waiting when its own context is done.
+	ch := c.group.DoChan(string(roomName), func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.Background(), sipRoomLoadTimeout)
+		defer cancel()
+		err := c.load(loadCtx, roomName)
+		if !errors.Is(err, ErrRoomNotFound) {
+			return nil, err
+		}
+		now := time.Now()
+		c.mu.Lock()
+		c.missing[roomName] = now.Add(c.ttl)
+		if now.Sub(c.lastPrune) > c.ttl {
+			c.pruneLocked(now)
+		}
+		c.mu.Unlock()
+		return nil, err
+	})
+	select {
+	case res := <-ch:
+		return res.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pruneLocked removes expired entries. Individual dispatch rules create a room per caller,
+// so without pruning the map would grow with every distinct number.
+func (c *sipRoomCache) pruneLocked(now time.Time) {
+	for name, expires := range c.missing {
+		if now.After(expires) {
+			delete(c.missing, name)
+		}
+	}
+	c.lastPrune = now
+}
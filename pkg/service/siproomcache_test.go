@@ -0,0 +1,166 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/routing"
+)
+
+func TestSIPRoomCache(t *testing.T) {
+	t.Run("concurrent lookups share one load", func(t *testing.T) {
+		var loads atomic.Int32
+		release := make(chan struct{})
+		c := newSIPRoomCache(time.Minute, func(ctx context.Context, roomName livekit.RoomName) error {
+			loads.Inc()
+			<-release
+			return nil
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, c.Validate(context.Background(), "conference"))
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		require.Equal(t, int32(1), loads.Load())
+
+		// rooms that exist are not cached
+		require.NoError(t, c.Validate(context.Background(), "conference"))
+		require.Equal(t, int32(2), loads.Load())
+	})
+
+	t.Run("cancelled caller does not fail the others", func(t *testing.T) {
+		release := make(chan struct{})
+		c := newSIPRoomCache(time.Minute, func(ctx context.Context, roomName livekit.RoomName) error {
+			select {
+			case <-release:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error, 1)
+		go func() { first <- c.Validate(ctx, "conference") }()
+		time.Sleep(20 * time.Millisecond)
+		second := make(chan error, 1)
+		go func() { second <- c.Validate(context.Background(), "conference") }()
+		time.Sleep(20 * time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-first, context.Canceled)
+		close(release)
+		require.NoError(t, <-second)
+	})
+
+	t.Run("missing rooms are cached", func(t *testing.T) {
+		var loads atomic.Int32
+		c := newSIPRoomCache(time.Minute, func(ctx context.Context, roomName livekit.RoomName) error {
+			loads.Inc()
+			return ErrRoomNotFound
+		})
+		require.ErrorIs(t, c.Validate(context.Background(), "missing"), ErrRoomNotFound)
+		require.ErrorIs(t, c.Validate(context.Background(), "missing"), ErrRoomNotFound)
+		require.Equal(t, int32(1), loads.Load())
+	})
+
+	t.Run("other errors are not cached", func(t *testing.T) {
+		var loads atomic.Int32
+		c := newSIPRoomCache(time.Minute, func(ctx context.Context, roomName livekit.RoomName) error {
+			loads.Inc()
+			return routing.ErrNodeLimitReached
+		})
+		require.ErrorIs(t, c.Validate(context.Background(), "full"), routing.ErrNodeLimitReached)
+		require.ErrorIs(t, c.Validate(context.Background(), "full"), routing.ErrNodeLimitReached)
+		require.Equal(t, int32(2), loads.Load())
+	})
+
+	t.Run("deleted room", func(t *testing.T) {
+		var deleted atomic.Bool
+		c := newSIPRoomCache(time.Minute, func(ctx context.Context, roomName livekit.RoomName) error {
+			if deleted.Load() {
+				return ErrRoomNotFound
+			}
+			return nil
+		})
+		require.NoError(t, c.Validate(context.Background(), "room"))
+		deleted.Store(true)
+		require.ErrorIs(t, c.Validate(context.Background(), "room"), ErrRoomNotFound)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		var loads atomic.Int32
+		c := newSIPRoomCache(10*time.Millisecond, func(ctx context.Context, roomName livekit.RoomName) error {
+			loads.Inc()
+			return ErrRoomNotFound
+		})
+		require.ErrorIs(t, c.Validate(context.Background(), "room"), ErrRoomNotFound)
+		time.Sleep(20 * time.Millisecond)
+		require.ErrorIs(t, c.Validate(context.Background(), "room"), ErrRoomNotFound)
+		require.Equal(t, int32(2), loads.Load())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var loads atomic.Int32
+		c := newSIPRoomCache(0, func(ctx context.Context, roomName livekit.RoomName) error {
+			loads.Inc()
+			return nil
+		})
+		require.NoError(t, c.Validate(context.Background(), "room"))
+		require.NoError(t, c.Validate(context.Background(), "room"))
+		require.Equal(t, int32(2), loads.Load())
+	})
+}
+
+// BenchmarkSIPRoomCache simulates a spike of concurrent inbound calls to the same room and reports
+// how many times the underlying room lookup was executed.
+func BenchmarkSIPRoomCache(b *testing.B) {
+	for _, ttl := range []time.Duration{0, time.Minute} {
+		b.Run(ttl.String(), func(b *testing.B) {
+			var loads atomic.Int32
+			c := newSIPRoomCache(ttl, func(ctx context.Context, roomName livekit.RoomName) error {
+				loads.Inc()
+				time.Sleep(time.Millisecond)
+				return nil
+			})
+			b.SetParallelism(100)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = c.Validate(context.Background(), "conference")
+				}
+			})
+			b.ReportMetric(float64(loads.Load()), "lookups")
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	sutils "github.com/livekit/livekit-server/pkg/utils"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/psrpc"
+)
+
+const (
+	sipScheduledCallPrefix  = "SSC_"
+	sipSchedulePollInterval = time.Second
+	// scheduled calls dialed at once by each server
+	sipScheduledCallDials = 10
+	// used when sip.dial_timeout is not set
+	sipScheduledCallDialTimeout = time.Minute
+)
+
+// SIPScheduledCall is an outbound call that will be dialed at ScheduledAt.
+type SIPScheduledCall struct {
+	ID          string
+	ScheduledAt time.Time
+	Request     *livekit.CreateSIPParticipantRequest
+}
+
+type sipScheduledCallJSON struct {
+	ID          string          `json:"id"`
+	ScheduledAt time.Time       `json:"scheduled_at"`
+	Request     json.RawMessage `json:"request"`
+}
+
+func (c *SIPScheduledCall) MarshalJSON() ([]byte, error) {
+	req, err := protojson.Marshal(c.Request)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(sipScheduledCallJSON{ID: c.ID, ScheduledAt: c.ScheduledAt, Request: req})
+}
+
+func (c *SIPScheduledCall) UnmarshalJSON(data []byte) error {
+	var in sipScheduledCallJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	req := &livekit.CreateSIPParticipantRequest{}
+	if err := protojson.Unmarshal(in.Request, req); err != nil {
+		return err
+	}
+	c.ID, c.ScheduledAt, c.Request = in.ID, in.ScheduledAt, req
+	return nil
+}
+
+// ScheduleSIPParticipant stores a call to be dialed at a future time. Calls are kept in the store, so they survive
+// restarts, and are dialed by whichever server claims them first. Quotas and dial pacing are applied when the call
+// is dialed. Use CreateSIPParticipant to dial immediately.
+func (s *SIPService) ScheduleSIPParticipant(ctx context.Context, req *livekit.CreateSIPParticipantRequest, at time.Time) (*SIPScheduledCall, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+
+	if !at.After(time.Now()) {
+		return nil, psrpc.NewErrorf(psrpc.InvalidArgument, "scheduled time %s is not in the future", at.Format(time.RFC3339))
+	}
+	if req.SipTrunkId != "" {
+		if err := sipValidateID("sip_trunk_id", req.SipTrunkId, utils.SIPTrunkPrefix); err != nil {
+			return nil, err
+		}
+	}
+
+	call := &SIPScheduledCall{
+		ID:          utils.NewGuid(sipScheduledCallPrefix),
+		ScheduledAt: at,
+		Request:     req,
+	}
+	if err := s.store.StoreSIPScheduledCall(ctx, call); err != nil {
+		return nil, err
+	}
+	sipScheduledCallLogger(call.ID).Infow("SIP call scheduled", "scheduledAt", at, "sipTrunkID", req.SipTrunkId, "room", req.RoomName)
+	return call, nil
+}
+
+// ListSIPScheduledCalls returns the calls that have not been dialed yet or are being dialed, earliest first.
+func (s *SIPService) ListSIPScheduledCalls(ctx context.Context) ([]*SIPScheduledCall, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if s.store == nil {
+		return nil, ErrSIPNotConnected
+	}
+
+	calls, err := s.store.ListSIPScheduledCalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].ScheduledAt.Before(calls[j].ScheduledAt) })
+	return calls, nil
+}
+
+// CancelSIPScheduledCall removes a call that is not being dialed yet.
+func (s *SIPService) CancelSIPScheduledCall(ctx context.Context, id string) error {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return twirpAuthError(err)
+	}
+	if s.store == nil {
+		return ErrSIPNotConnected
+	}
+
+	if err := sipValidateID("scheduled_call_id", id, sipScheduledCallPrefix); err != nil {
+		return err
+	}
+	if err := s.store.CancelSIPScheduledCall(ctx, id); err != nil {
+		return err
+	}
+	sipScheduledCallLogger(id).Infow("SIP scheduled call cancelled")
+	return nil
+}
+
+// Start dials scheduled calls as they become due.
+func (s *SIPService) Start() {
+	if s.store == nil {
+		return
+	}
+	s.workers.Add(1)
+	go s.scheduleWorker()
+}
+
+// Stop stops dialing scheduled calls and waits for dials in progress. Calls interrupted by the shutdown are
+// dialed again once their lease expires.
+func (s *SIPService) Stop() {
+	s.stopWorkers()
+	s.workers.Wait()
+}
+
+func (s *SIPService) scheduleWorker() {
+	defer s.workers.Done()
+	ticker := time.NewTicker(sipSchedulePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.workerCtx.Done():
+			return
+		case <-ticker.C:
+			s.dialScheduledCalls(time.Now())
+		}
+	}
+}
+
+// dialScheduledCalls claims as many due calls as there are free dial slots and starts dialing them.
+// A call stays in the store until its dial finished, so a call claimed by a server that stopped is claimed
+// again by another once the lease expires.
+func (s *SIPService) dialScheduledCalls(now time.Time) {
+	free := cap(s.dialSlots) - len(s.dialSlots)
+	if free == 0 {
+		return
+	}
+	calls, err := s.store.ClaimSIPScheduledCalls(s.workerCtx, now, now.Add(s.scheduledCallLease()), free)
+	if err != nil {
+		logger.GetLogger().WithComponent(sutils.ComponentSIP).Warnw("could not claim SIP scheduled calls", err)
+		return
+	}
+
+	for _, call := range calls {
+		// only this goroutine takes slots, so this never blocks
+		s.dialSlots <- struct{}{}
+		s.workers.Add(1)
+		go func(call *SIPScheduledCall) {
+			defer func() {
+				<-s.dialSlots
+				s.workers.Done()
+			}()
+			s.dialScheduledCall(call, now)
+		}(call)
+	}
+}
+
+func (s *SIPService) dialScheduledCall(call *SIPScheduledCall, now time.Time) {
+	log := sipScheduledCallLogger(call.ID).WithValues("sipTrunkID", call.Request.SipTrunkId, "room", call.Request.RoomName)
+	log.Infow("dialing SIP scheduled call", "delay", now.Sub(call.ScheduledAt))
+
+	ctx, cancel := context.WithTimeout(s.workerCtx, s.scheduledCallDialTimeout())
+	_, err := s.createSIPParticipant(ctx, call.Request)
+	cancel()
+	if err != nil && s.workerCtx.Err() != nil {
+		log.Infow("SIP scheduled call interrupted by shutdown, it will be dialed again", "error", err)
+		return
+	}
+	if err != nil {
+		// failed dials are not retried, the same as for CreateSIPParticipant
+		log.Warnw("SIP scheduled call failed", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), sipCleanupTimeout)
+	defer cancel()
+	if err := s.store.CompleteSIPScheduledCall(ctx, call.ID); err != nil {
+		log.Warnw("could not complete SIP scheduled call", err)
+	}
+}
+
+// scheduledCallDialTimeout is the longest a scheduled dial may take, the same as for CreateSIPParticipant.
+func (s *SIPService) scheduledCallDialTimeout() time.Duration {
+	if s.conf.DialTimeout > 0 {
+		return s.conf.DialTimeout
+	}
+	return sipScheduledCallDialTimeout
+}
+
+// scheduledCallLease is longer than a dial may take, so that a call is only claimed again when its server stopped.
+func (s *SIPService) scheduledCallLease() time.Duration {
+	return 2 * s.scheduledCallDialTimeout()
+}
+
+func sipScheduledCallLogger(id string) logger.Logger {
+	return logger.GetLogger().WithComponent(sutils.ComponentSIP).WithValues("scheduledCallID", id)
+}
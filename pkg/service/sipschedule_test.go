@@ -0,0 +1,204 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+type sipScheduleStore struct {
+	SIPStore
+	mu           sync.Mutex
+	calls        map[string][]byte
+	leases       map[string]time.Time
+	participants chan *livekit.SIPParticipantInfo
+}
+
+func (s *sipScheduleStore) StoreSIPScheduledCall(ctx context.Context, call *SIPScheduledCall) error {
+	data, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[call.ID] = data
+	return nil
+}
+
+func (s *sipScheduleStore) ListSIPScheduledCalls(ctx context.Context) ([]*SIPScheduledCall, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*SIPScheduledCall
+	for _, data := range s.calls {
+		call := &SIPScheduledCall{}
+		if err := json.Unmarshal(data, call); err != nil {
+			return nil, err
+		}
+		out = append(out, call)
+	}
+	return out, nil
+}
+
+func (s *sipScheduleStore) ClaimSIPScheduledCalls(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*SIPScheduledCall, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*SIPScheduledCall
+	for id, data := range s.calls {
+		if len(out) == limit {
+			break
+		}
+		if lease, ok := s.leases[id]; ok && lease.After(now) {
+			continue
+		}
+		call := &SIPScheduledCall{}
+		if err := json.Unmarshal(data, call); err != nil {
+			return nil, err
+		}
+		if call.ScheduledAt.After(now) {
+			continue
+		}
+		s.leases[id] = leaseUntil
+		out = append(out, call)
+	}
+	return out, nil
+}
+
+func (s *sipScheduleStore) CompleteSIPScheduledCall(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.leases, id)
+	delete(s.calls, id)
+	return nil
+}
+
+func (s *sipScheduleStore) CancelSIPScheduledCall(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.calls[id]; !ok {
+		return ErrSIPScheduledCallMissing
+	}
+	if _, ok := s.leases[id]; ok {
+		return ErrSIPScheduledCallMissing
+	}
+	delete(s.calls, id)
+	return nil
+}
+
+func (s *sipScheduleStore) LoadSIPTrunk(ctx context.Context, sipTrunkID string) (*livekit.SIPTrunkInfo, error) {
+	return &livekit.SIPTrunkInfo{SipTrunkId: sipTrunkID}, nil
+}
+
+func (s *sipScheduleStore) StoreSIPParticipant(ctx context.Context, info *livekit.SIPParticipantInfo) error {
+	s.participants <- info
+	return nil
+}
+
+func TestSIPScheduledCalls(t *testing.T) {
+	ctx := WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{RoomCreate: true}})
+	store := &sipScheduleStore{
+		calls:        make(map[string][]byte),
+		leases:       make(map[string]time.Time),
+		participants: make(chan *livekit.SIPParticipantInfo, 10),
+	}
+	svc := NewSIPService(&config.SIPConfig{}, "node", nil, nil, store, nil, nil)
+	req := &livekit.CreateSIPParticipantRequest{SipTrunkId: "ST_aaa", RoomName: "campaign"}
+
+	_, err := svc.ScheduleSIPParticipant(ctx, req, time.Now().Add(-time.Minute))
+	require.Error(t, err)
+
+	now := time.Now()
+	later, err := svc.ScheduleSIPParticipant(ctx, req, now.Add(time.Hour))
+	require.NoError(t, err)
+	soon, err := svc.ScheduleSIPParticipant(ctx, req, now.Add(time.Minute))
+	require.NoError(t, err)
+	cancelled, err := svc.ScheduleSIPParticipant(ctx, req, now.Add(2*time.Minute))
+	require.NoError(t, err)
+
+	require.NoError(t, svc.CancelSIPScheduledCall(ctx, cancelled.ID))
+	require.ErrorIs(t, svc.CancelSIPScheduledCall(ctx, cancelled.ID), ErrSIPScheduledCallMissing)
+
+	calls, err := svc.ListSIPScheduledCalls(ctx)
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+	require.Equal(t, soon.ID, calls[0].ID)
+	require.Equal(t, later.ID, calls[1].ID)
+	require.Equal(t, "campaign", calls[0].Request.RoomName)
+
+	// a second server polling at the same time does not dial the call again
+	other := NewSIPService(&config.SIPConfig{}, "other", nil, nil, store, nil, nil)
+	svc.dialScheduledCalls(now.Add(5 * time.Minute))
+	other.dialScheduledCalls(now.Add(5 * time.Minute))
+	svc.workers.Wait()
+	other.workers.Wait()
+
+	select {
+	case <-store.participants:
+	case <-time.After(time.Second):
+		t.Fatal("scheduled call was not dialed")
+	}
+	select {
+	case <-store.participants:
+		t.Fatal("scheduled call was dialed twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	calls, err = svc.ListSIPScheduledCalls(ctx)
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	require.Equal(t, later.ID, calls[0].ID)
+
+	// a call claimed by a server that stopped before finishing the dial is claimed again once the lease expires
+	store.leases[later.ID] = now.Add(time.Hour + time.Minute)
+	other.dialScheduledCalls(now.Add(time.Hour + 30*time.Second))
+	other.workers.Wait()
+	require.ErrorIs(t, svc.CancelSIPScheduledCall(ctx, later.ID), ErrSIPScheduledCallMissing)
+	select {
+	case <-store.participants:
+		t.Fatal("scheduled call was claimed while leased")
+	default:
+	}
+	other.dialScheduledCalls(now.Add(time.Hour + 2*time.Minute))
+	other.workers.Wait()
+	select {
+	case <-store.participants:
+	default:
+		t.Fatal("scheduled call was not claimed again after the lease expired")
+	}
+
+	calls, err = svc.ListSIPScheduledCalls(ctx)
+	require.NoError(t, err)
+	require.Empty(t, calls)
+}
+
+func TestSIPScheduledCallLease(t *testing.T) {
+	svc := NewSIPService(&config.SIPConfig{}, "node", nil, nil, nil, nil, nil)
+	require.Equal(t, sipScheduledCallDialTimeout, svc.scheduledCallDialTimeout())
+	require.Equal(t, 2*sipScheduledCallDialTimeout, svc.scheduledCallLease())
+
+	svc = NewSIPService(&config.SIPConfig{DialTimeout: 5 * time.Minute}, "node", nil, nil, nil, nil, nil)
+	require.Equal(t, 5*time.Minute, svc.scheduledCallDialTimeout())
+	require.Equal(t, 10*time.Minute, svc.scheduledCallLease())
+}
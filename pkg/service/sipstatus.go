@@ -0,0 +1,159 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// status is cached so that load balancer health checks do not hit the store on every request
+	sipStatusCacheTTL = 2 * time.Second
+	sipStatusTimeout  = time.Second
+)
+
+const (
+	SIPStatusUp   = "up"
+	SIPStatusDown = "down"
+	// no SIP store is configured, SIP is not in use
+	SIPStatusDisabled = "disabled"
+)
+
+type SIPStatus struct {
+	Status         string    `json:"status"`
+	StoreConnected bool      `json:"store_connected"`
+	Trunks         int       `json:"trunks"`
+	DispatchRules  int       `json:"dispatch_rules"`
+	Participants   int       `json:"participants"`
+	DialsQueued    int       `json:"dials_queued"`
+	Warnings       []string  `json:"warnings,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type sipStatusCache struct {
+	// concurrent requests on an expired cache share one refresh
+	group singleflight.Group
+
+	mu     sync.Mutex
+	status *SIPStatus
+}
+
+// GetSIPStatus reports the state of the SIP subsystem. Components that cannot be reached are reported
+// as warnings instead of failing the whole request.
+func (s *SIPService) GetSIPStatus(ctx context.Context) *SIPStatus {
+	s.status.mu.Lock()
+	st := s.status.status
+	s.status.mu.Unlock()
+	if st != nil && time.Since(st.UpdatedAt) < sipStatusCacheTTL {
+		return st
+	}
+
+	// the refresh is shared, so it does not use the context of the request that started it
+	ch := s.status.group.DoChan("status", func() (interface{}, error) {
+		st := s.loadSIPStatus()
+		s.status.mu.Lock()
+		s.status.status = st
+		s.status.mu.Unlock()
+		return st, nil
+	})
+	select {
+	case res := <-ch:
+		return res.Val.(*SIPStatus)
+	case <-ctx.Done():
+		st := &SIPStatus{
+			StoreConnected: s.store != nil,
+			DialsQueued:    s.dialQueue.TotalWaiting(),
+			Warnings:       []string{fmt.Sprintf("cannot load sip status: %v", ctx.Err())},
+			UpdatedAt:      time.Now(),
+		}
+		st.Status = sipStatusOf(st)
+		return st
+	}
+}
+
+// sipStatusOf is up when every component could be reached.
+func sipStatusOf(st *SIPStatus) string {
+	switch {
+	case !st.StoreConnected:
+		return SIPStatusDisabled
+	case len(st.Warnings) != 0:
+		return SIPStatusDown
+	default:
+		return SIPStatusUp
+	}
+}
+
+func (s *SIPService) loadSIPStatus() *SIPStatus {
+	st := &SIPStatus{
+		StoreConnected: s.store != nil,
+		DialsQueued:    s.dialQueue.TotalWaiting(),
+		UpdatedAt:      time.Now(),
+	}
+	if s.store == nil {
+		st.Warnings = append(st.Warnings, ErrSIPNotConnected.Error())
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), sipStatusTimeout)
+		defer cancel()
+
+		if trunks, err := s.store.ListSIPTrunk(ctx); err != nil {
+			st.Warnings = append(st.Warnings, fmt.Sprintf("cannot list sip trunks: %v", err))
+		} else {
+			st.Trunks = len(trunks)
+		}
+		if rules, err := s.store.ListSIPDispatchRule(ctx); err != nil {
+			st.Warnings = append(st.Warnings, fmt.Sprintf("cannot list sip dispatch rules: %v", err))
+		} else {
+			st.DispatchRules = len(rules)
+		}
+		if participants, err := s.store.ListSIPParticipant(ctx); err != nil {
+			st.Warnings = append(st.Warnings, fmt.Sprintf("cannot list sip participants: %v", err))
+		} else {
+			st.Participants = len(participants)
+		}
+	}
+
+	st.Status = sipStatusOf(st)
+	return st
+}
+
+// ServeStatus writes the SIP status as JSON. Without a token with SIP admin permission only the up, down or disabled
+// status is written, for load balancer health checks. It responds with 503 when the status is down.
+func (s *SIPService) ServeStatus(w http.ResponseWriter, r *http.Request) {
+	st := s.GetSIPStatus(r.Context())
+	var res interface{} = st
+	if err := EnsureSIPAdminPermission(r.Context()); err != nil {
+		res = &struct {
+			Status string `json:"status"`
+		}{Status: st.Status}
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		handleError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if st.Status == SIPStatusDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_, _ = w.Write(b)
+}
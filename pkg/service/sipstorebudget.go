@@ -0,0 +1,77 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+const (
+	sipStorePathCallSetup = "call_setup"
+	sipStorePathList      = "list"
+)
+
+// sipStoreBudget bounds the number of concurrent SIP store operations on one path.
+// Operations on a waiting path queue for a slot until their context is done, others are rejected right away,
+// so that listing cannot queue up behind call setup and hold the store for longer.
+type sipStoreBudget struct {
+	path  string
+	wait  bool
+	slots chan struct{}
+}
+
+// newSIPStoreBudget returns nil, which allows everything, if limit is 0.
+func newSIPStoreBudget(path string, limit int, wait bool) *sipStoreBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &sipStoreBudget{
+		path:  path,
+		wait:  wait,
+		slots: make(chan struct{}, limit),
+	}
+}
+
+// Acquire returns a function that must be called once the store operation is done.
+func (b *sipStoreBudget) Acquire(ctx context.Context) (func(), error) {
+	if b == nil {
+		return func() {}, nil
+	}
+	release := func() { <-b.slots }
+
+	select {
+	case b.slots <- struct{}{}:
+		prometheus.SIPStoreWait(b.path, 0)
+		return release, nil
+	default:
+	}
+	if !b.wait {
+		prometheus.SIPStoreRejected(b.path)
+		return nil, ErrSIPStoreBusy
+	}
+
+	start := time.Now()
+	select {
+	case b.slots <- struct{}{}:
+		prometheus.SIPStoreWait(b.path, time.Since(start))
+		return release, nil
+	case <-ctx.Done():
+		prometheus.SIPStoreRejected(b.path)
+		return nil, ctx.Err()
+	}
+}
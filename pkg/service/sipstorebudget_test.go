@@ -0,0 +1,63 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSIPStoreBudget(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		b := newSIPStoreBudget(sipStorePathList, 0, false)
+		for i := 0; i < 10; i++ {
+			_, err := b.Acquire(context.Background())
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("list rejects", func(t *testing.T) {
+		b := newSIPStoreBudget(sipStorePathList, 2, false)
+		r1, err := b.Acquire(context.Background())
+		require.NoError(t, err)
+		_, err = b.Acquire(context.Background())
+		require.NoError(t, err)
+		_, err = b.Acquire(context.Background())
+		require.ErrorIs(t, err, ErrSIPStoreBusy)
+
+		r1()
+		_, err = b.Acquire(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("call setup waits", func(t *testing.T) {
+		b := newSIPStoreBudget(sipStorePathCallSetup, 1, true)
+		release, err := b.Acquire(context.Background())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = b.Acquire(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		time.AfterFunc(20*time.Millisecond, release)
+		release, err = b.Acquire(context.Background())
+		require.NoError(t, err)
+		release()
+	})
+}
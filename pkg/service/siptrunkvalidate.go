@@ -0,0 +1,266 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/psrpc"
+)
+
+// SIPTrunkProblem describes a single problem with a trunk definition. Warnings are reported by ValidateSIPTrunk,
+// but do not fail a create.
+type SIPTrunkProblem struct {
+	Field   string
+	Message string
+	Warning bool
+}
+
+func (p SIPTrunkProblem) String() string {
+	return p.Field + ": " + p.Message
+}
+
+// ValidateSIPTrunk runs every check CreateSIPTrunk does and returns all problems found, without storing anything.
+// An error is only returned if the existing trunks cannot be loaded.
+func (s *SIPService) ValidateSIPTrunk(ctx context.Context, req *livekit.CreateSIPTrunkRequest) ([]SIPTrunkProblem, error) {
+	if err := EnsureSIPAdminPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	problems := sipValidateTrunkFields(req)
+	if s.store == nil {
+		return problems, nil
+	}
+
+	trunks, err := s.store.ListSIPTrunk(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(problems, sipTrunkConflicts(req, "", trunks)...), nil
+}
+
+// sipTrunkConflicts returns a problem for every trunk that would match the same calls as req, and a warning for
+// every trunk that may. The trunk with ID replaces is skipped, so that a trunk being updated does not conflict
+// with itself.
+func sipTrunkConflicts(req *livekit.CreateSIPTrunkRequest, replaces string, trunks []*livekit.SIPTrunkInfo) []SIPTrunkProblem {
+	var problems []SIPTrunkProblem
+	for _, t := range trunks {
+		if t.SipTrunkId == replaces {
+			continue
+		}
+		conflict, undecided := sipTrunksConflict(req, t)
+		switch {
+		case conflict && t.OutboundNumber == "":
+			problems = append(problems, SIPTrunkProblem{
+				Field:   "outbound_number",
+				Message: fmt.Sprintf("default trunk %s already accepts calls from the same numbers and inbound addresses", t.SipTrunkId),
+			})
+		case conflict:
+			problems = append(problems, SIPTrunkProblem{
+				Field:   "outbound_number",
+				Message: fmt.Sprintf("trunk %s already uses this number for the same calling numbers and inbound addresses", t.SipTrunkId),
+			})
+		case undecided:
+			problems = append(problems, SIPTrunkProblem{
+				Field:   "inbound_numbers_regex",
+				Message: fmt.Sprintf("trunk %s accepts calls for the same number and inbound addresses, calls are rejected if both trunks' patterns match the calling number", t.SipTrunkId),
+				Warning: true,
+			})
+		}
+	}
+	return problems
+}
+
+// sipTrunkRequest returns the create request matching a stored trunk, for validating trunks that do not come from
+// a request.
+func sipTrunkRequest(info *livekit.SIPTrunkInfo) *livekit.CreateSIPTrunkRequest {
+	return &livekit.CreateSIPTrunkRequest{
+		InboundAddresses:    info.InboundAddresses,
+		OutboundAddress:     info.OutboundAddress,
+		OutboundNumber:      info.OutboundNumber,
+		InboundNumbersRegex: info.InboundNumbersRegex,
+		Username:            info.Username,
+		Password:            info.Password,
+	}
+}
+
+func sipValidateTrunkFields(req *livekit.CreateSIPTrunkRequest) []SIPTrunkProblem {
+	var problems []SIPTrunkProblem
+	add := func(field, format string, args ...interface{}) {
+		problems = append(problems, SIPTrunkProblem{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, addr := range req.InboundAddresses {
+		if net.ParseIP(addr) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(addr); err != nil {
+			add("inbound_addresses", "%q is not an IP address or CIDR", addr)
+		}
+	}
+	if req.OutboundAddress != "" {
+		if msg := sipValidateHostPort(req.OutboundAddress); msg != "" {
+			add("outbound_address", "%q %s", req.OutboundAddress, msg)
+		}
+	}
+	if req.OutboundNumber != "" && !sipValidNumber(req.OutboundNumber) {
+		add("outbound_number", "%q is not a phone number", req.OutboundNumber)
+	}
+	for _, re := range req.InboundNumbersRegex {
+		if _, err := regexp.Compile(re); err != nil {
+			add("inbound_numbers_regex", "%q does not compile: %v", re, err)
+		}
+	}
+	return problems
+}
+
+func sipValidateHostPort(addr string) string {
+	if strings.Contains(addr, "://") || strings.HasPrefix(addr, "sip:") {
+		return "must be a host or host:port, without a scheme"
+	}
+	if strings.ContainsAny(addr, " \t\r\n") {
+		return "must not contain whitespace"
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		// no port
+		host, port = addr, ""
+	}
+	if host == "" {
+		return "has no host"
+	}
+	if port != "" {
+		if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+			return "has an invalid port"
+		}
+	}
+	return ""
+}
+
+// sipValidNumber accepts digits with an optional leading plus, allowing common separators.
+func sipValidNumber(num string) bool {
+	digits := 0
+	for i, c := range num {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '+' && i == 0:
+		case c == ' ' || c == '-' || c == '(' || c == ')' || c == '.':
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// sipTrunksConflict reports whether a new trunk would match the same calls as an existing one, following
+// sipMatchTrunk: trunks for the same number, or two default trunks, are ambiguous for the calling numbers and
+// source addresses both accept. Whether two different sets of number patterns match a common number is not
+// checked, and reported as undecided instead.
+func sipTrunksConflict(req *livekit.CreateSIPTrunkRequest, t *livekit.SIPTrunkInfo) (conflict, undecided bool) {
+	if req.OutboundNumber != t.OutboundNumber {
+		// a trunk for the called number is preferred to a default trunk
+		return false, false
+	}
+	if !sipAddressesOverlap(req.InboundAddresses, t.InboundAddresses) {
+		return false, false
+	}
+	a, b := sipValidPatterns(req.InboundNumbersRegex), sipValidPatterns(t.InboundNumbersRegex)
+	switch {
+	case len(a) == 0 && len(req.InboundNumbersRegex) != 0, len(b) == 0 && len(t.InboundNumbersRegex) != 0:
+		// none of the patterns compile, the trunk matches no calls
+		return false, false
+	case len(a) == 0 || len(b) == 0:
+		// one of the trunks accepts every calling number
+		return true, false
+	}
+	for _, re := range a {
+		if slices.Contains(b, re) {
+			return true, false
+		}
+	}
+	return false, true
+}
+
+func sipValidPatterns(patterns []string) []string {
+	var valid []string
+	for _, re := range patterns {
+		if _, err := sipCompileRegex(re); err == nil {
+			valid = append(valid, re)
+		}
+	}
+	return valid
+}
+
+// sipAddressesOverlap reports whether a source address is accepted by both address lists, where an empty list
+// accepts every address.
+func sipAddressesOverlap(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		nx := sipParseNet(x)
+		if nx == nil {
+			continue
+		}
+		for _, y := range b {
+			// CIDR blocks overlap only if one contains the other
+			if ny := sipParseNet(y); ny != nil && (nx.Contains(ny.IP) || ny.Contains(nx.IP)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sipParseNet parses an inbound address, treating a single IP as a network of one address.
+func sipParseNet(addr string) *net.IPNet {
+	if strings.Contains(addr, "/") {
+		_, ipNet, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil
+		}
+		return ipNet
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(8*len(ip), 8*len(ip))}
+}
+
+// sipTrunkProblemsError returns an error for the problems that are not warnings, nil if there are none.
+func sipTrunkProblemsError(problems []SIPTrunkProblem) error {
+	msgs := make([]string, 0, len(problems))
+	for _, p := range problems {
+		if !p.Warning {
+			msgs = append(msgs, p.String())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return psrpc.NewErrorf(psrpc.InvalidArgument, "invalid sip trunk: %s", strings.Join(msgs, "; "))
+}
@@ -202,7 +202,7 @@ func getIngressConfig(conf *config.Config) *config.IngressConfig {
 func getSIPStore(s ObjectStore) SIPStore {
 	switch store := s.(type) {
 	case *RedisStore:
-		return store
+		return newSIPRetryStore(store)
 	default:
 		return nil
 	}
@@ -77,7 +77,7 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 	}
 	analyticsService := telemetry.NewAnalyticsService(conf, currentNode)
 	telemetryService := telemetry.NewTelemetryService(queuedNotifier, analyticsService)
-	ioInfoService, err := NewIOInfoService(messageBus, egressStore, ingressStore, sipStore, telemetryService)
+	ioInfoService, err := NewIOInfoService(messageBus, egressStore, ingressStore, sipStore, roomAllocator, router, conf, telemetryService)
 	if err != nil {
 		return nil, err
 	}
@@ -247,7 +247,7 @@ func getIngressConfig(conf *config.Config) *config.IngressConfig {
 func getSIPStore(s ObjectStore) SIPStore {
 	switch store := s.(type) {
 	case *RedisStore:
-		return store
+		return newSIPRetryStore(store)
 	default:
 		return nil
 	}
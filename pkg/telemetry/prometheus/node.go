@@ -110,6 +110,7 @@ func Init(nodeID string, nodeType livekit.NodeType, env string) {
 	initRoomStats(nodeID, nodeType, env)
 	initPSRPCStats(nodeID, nodeType, env)
 	initQualityStats(nodeID, nodeType, env)
+	initSIPStats(nodeID, nodeType, env)
 }
 
 func GetUpdatedNodeStats(prev *livekit.NodeStats, prevAverage *livekit.NodeStats) (*livekit.NodeStats, bool, error) {
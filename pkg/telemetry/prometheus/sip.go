@@ -0,0 +1,132 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promSIPDialQueueDepth *prometheus.GaugeVec
+	promSIPDialQueueWait  *prometheus.HistogramVec
+	promSIPDialRejected   *prometheus.CounterVec
+	promSIPDTMFRequests   *prometheus.CounterVec
+	promSIPDispatchFailed *prometheus.CounterVec
+	promSIPStoreWait      *prometheus.HistogramVec
+	promSIPStoreRejected  *prometheus.CounterVec
+	promSIPStoreRetries   *prometheus.CounterVec
+)
+
+func initSIPStats(nodeID string, nodeType livekit.NodeType, env string) {
+	promSIPDialQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "sip",
+		Name:        "dial_queue_depth",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String(), "env": env},
+	}, []string{"trunk"})
+	promSIPDialQueueWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "sip",
+		Name:        "dial_queue_wait_ms",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String(), "env": env},
+		Buckets:     []float64{10, 50, 100, 300, 500, 1000, 2000, 5000, 10000, 30000, 60000},
+	}, []string{"trunk"})
+	promSIPDialRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "sip",
+		Name:        "dial_rejected",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String(), "env": env},
+	}, []string{"trunk", "reason"})
+	promSIPDTMFRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "sip",
+		Name:        "dtmf_requests",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String(), "env": env},
+	}, []string{"status"})
+
+	promSIPDispatchFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "sip",
+		Name:        "dispatch_failed",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String(), "env": env},
+	}, []string{"trunk"})
+
+	promSIPStoreWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "sip",
+		Name:        "store_wait_ms",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String(), "env": env},
+		Buckets:     []float64{1, 5, 10, 50, 100, 500, 1000, 5000},
+	}, []string{"path"})
+	promSIPStoreRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "sip",
+		Name:        "store_rejected",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String(), "env": env},
+	}, []string{"path"})
+
+	promSIPStoreRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "sip",
+		Name:        "store_retries",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String(), "env": env},
+	}, []string{"op", "outcome"})
+
+	prometheus.MustRegister(promSIPDialQueueDepth)
+	prometheus.MustRegister(promSIPDialQueueWait)
+	prometheus.MustRegister(promSIPDialRejected)
+	prometheus.MustRegister(promSIPDTMFRequests)
+	prometheus.MustRegister(promSIPDispatchFailed)
+	prometheus.MustRegister(promSIPStoreWait)
+	prometheus.MustRegister(promSIPStoreRejected)
+	prometheus.MustRegister(promSIPStoreRetries)
+}
+
+func SIPDialQueued(trunkID string) {
+	promSIPDialQueueDepth.WithLabelValues(trunkID).Inc()
+}
+
+func SIPDialDequeued(trunkID string, wait time.Duration) {
+	promSIPDialQueueDepth.WithLabelValues(trunkID).Dec()
+	promSIPDialQueueWait.WithLabelValues(trunkID).Observe(float64(wait.Milliseconds()))
+}
+
+func SIPDialRejected(trunkID string, reason string) {
+	promSIPDialRejected.WithLabelValues(trunkID, reason).Inc()
+}
+
+func SIPDTMFRequest(status string) {
+	promSIPDTMFRequests.WithLabelValues(status).Inc()
+}
+
+func SIPDispatchFailed(trunkID string) {
+	promSIPDispatchFailed.WithLabelValues(trunkID).Inc()
+}
+
+func SIPStoreWait(path string, wait time.Duration) {
+	promSIPStoreWait.WithLabelValues(path).Observe(float64(wait.Milliseconds()))
+}
+
+func SIPStoreRejected(path string) {
+	promSIPStoreRejected.WithLabelValues(path).Inc()
+}
+
+func SIPStoreRetry(op string, outcome string) {
+	promSIPStoreRetries.WithLabelValues(op, outcome).Inc()
+}
@@ -23,6 +23,7 @@ const (
 	ComponentAPI       = "api"
 	ComponentTransport = "transport"
 	ComponentSFU       = "sfu"
+	ComponentSIP       = "sip"
 	// transport subcomponents
 	ComponentCongestionControl = "cc"
 )